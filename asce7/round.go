@@ -0,0 +1,177 @@
+package asce7
+
+import (
+	"fmt"
+	"math"
+)
+
+// Round is a round building: a cylindrical wall under a conical roof
+type Round struct {
+	Diameter   float64
+	EaveHeight float64
+	// RoofAngle is the slope of the cone from horizontal in degrees
+	RoofAngle float64
+}
+
+// building is the square building with the same section through its middle
+func (r Round) building() Building {
+	return Building{Width: r.Diameter, Length: r.Diameter, EaveHeight: r.EaveHeight, RoofAngle: r.RoofAngle}
+}
+
+// MeanRoofHeight is h, halfway up the cone
+func (r Round) MeanRoofHeight() float64 {
+	return r.building().MeanRoofHeight()
+}
+
+// Rise is f, the height of the top of the cone above the eave
+func (r Round) Rise() float64 {
+	return r.Diameter / 2 * math.Tan(r.RoofAngle*math.Pi/180)
+}
+
+// RoundCase is one of the wind load cases of a round building.  Pressures
+// are net of internal pressure and positive toward the surface.  A round
+// building looks the same from every direction so walls are located by
+// their angle around from the side facing the wind and the roof by its
+// distance along the wind.
+type RoundCase struct {
+	Name string
+	// GCpi is the internal pressure coefficient of the case
+	GCpi float64
+
+	round Round
+	qh    float64
+	// qt is the velocity pressure at the top of the roof, which the dome
+	// coefficients are used with
+	qt     float64
+	uplift bool
+	// caseB holds the coefficient of the windward edge up the first 25
+	// degrees of the roof
+	caseB bool
+}
+
+// minDomeRise is the smallest f/D given dome coefficients, flatter roofs
+// take those of a flat roof
+const minDomeRise = 0.05
+
+// RoundCases returns the wind load cases of a round building.  The cone is
+// taken as a dome of the same rise, figure 27.3-2, whose cases A and B are
+// each paired with positive and negative internal pressure.  Roofs flatter
+// than an f/D of 0.05 are flat roofs with the uplift and pressure cases of a
+// gable building.
+func (w Wind) RoundCases(r Round) ([]RoundCase, error) {
+	if w.Speed <= 0 {
+		return nil, fmt.Errorf("wind speed must be positive")
+	}
+	b := r.building()
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	if rise := r.Rise() / r.Diameter; rise > 0.5 {
+		return nil, fmt.Errorf("roofs rising %.2f of their diameter are steeper than the domes of figure 27.3-2", rise)
+	}
+	gcpi, err := w.Enclosure.GCpi()
+	if err != nil {
+		return nil, err
+	}
+	qh, err := w.VelocityPressure(b.MeanRoofHeight())
+	if err != nil {
+		return nil, err
+	}
+	qt, err := w.VelocityPressure(r.EaveHeight + r.Rise())
+	if err != nil {
+		return nil, err
+	}
+	if r.Rise()/r.Diameter < minDomeRise {
+		return []RoundCase{
+			{Name: "uplift", GCpi: gcpi, round: r, qh: qh, qt: qt, uplift: true},
+			{Name: "pressure", GCpi: -gcpi, round: r, qh: qh, qt: qt},
+		}, nil
+	}
+	return []RoundCase{
+		{Name: "A uplift", GCpi: gcpi, round: r, qh: qh, qt: qt, uplift: true},
+		{Name: "A pressure", GCpi: -gcpi, round: r, qh: qh, qt: qt},
+		{Name: "B uplift", GCpi: gcpi, round: r, qh: qh, qt: qt, uplift: true, caseB: true},
+		{Name: "B pressure", GCpi: -gcpi, round: r, qh: qh, qt: qt, caseB: true},
+	}, nil
+}
+
+func (c RoundCase) internal() float64 {
+	return -c.qh * c.GCpi
+}
+
+// roundWallCp is the external pressure coefficient theta degrees around a
+// cylindrical wall from the windward side, 29.4.2.1.  Suction on walls shorter
+// than their diameter is reduced by kb.
+func roundWallCp(theta, heightRatio float64) float64 {
+	t := theta * math.Pi / 180
+	cp := -0.5 + 0.4*math.Cos(t) + 0.8*math.Cos(2*t) + 0.3*math.Cos(3*t) - 0.1*math.Cos(4*t) - 0.05*math.Cos(5*t)
+	if cp < -0.15 {
+		heightRatio = math.Min(math.Max(heightRatio, 0.25), 4)
+		cp *= 1 - 0.55*(cp+0.15)*math.Log10(heightRatio)
+	}
+	return cp
+}
+
+// WallPressure is the net pressure on the wall theta degrees around from the
+// windward side
+func (c RoundCase) WallPressure(theta float64) float64 {
+	cp := roundWallCp(theta, c.round.EaveHeight/c.round.Diameter)
+	return c.qh*GustFactor*cp + c.internal()
+}
+
+// dome coefficients of figure 27.3-2 at A, the windward edge, B, the top, and
+// C, the leeward edge, for hD/D of 0, 0.25 and 0.5, along the lines of the
+// figure at f/D of 0.2 and 0.5
+var (
+	domeEaveRatios = []float64{0, 0.25, 0.5}
+	domeRiseRatios = []float64{0.2, 0.5}
+
+	domeA = [][]float64{{0.2, 0.8}, {-0.2, 0.6}, {-0.6, 0.4}}
+	domeB = [][]float64{{-0.6, -1.2}, {-0.8, -1.4}, {-1.0, -1.6}}
+	domeC = [][]float64{{-0.4, -0.3}, {-0.5, -0.4}, {-0.6, -0.5}}
+)
+
+// domeTableCp interpolates a line of dome coefficients in f/D and then in
+// hD/D, holding the values of f/D of 0.2 below it
+func domeTableCp(table [][]float64, eaveRatio, riseRatio float64) float64 {
+	byEave := make([]float64, len(domeEaveRatios))
+	for i, row := range table {
+		byEave[i] = interpolate(riseRatio, domeRiseRatios, row)
+	}
+	return interpolate(eaveRatio, domeEaveRatios, byEave)
+}
+
+// domeCp is the coefficient of a dome at theta degrees up the arc along the
+// wind from its windward edge, 90 at the top and 180 at the leeward edge.
+// Case A varies linearly from A to B to C and case B holds A up to 25
+// degrees.
+func domeCp(eaveRatio, riseRatio, theta float64, caseB bool) float64 {
+	a := domeTableCp(domeA, eaveRatio, riseRatio)
+	b := domeTableCp(domeB, eaveRatio, riseRatio)
+	c := domeTableCp(domeC, eaveRatio, riseRatio)
+	if caseB {
+		return interpolate(theta, []float64{0, 25, 90, 180}, []float64{a, a, b, c})
+	}
+	return interpolate(theta, []float64{0, 90, 180}, []float64{a, b, c})
+}
+
+// RoofPressure is the net pressure on the roof distance along the wind from
+// its windward edge.  The coefficients are constant on arcs across the wind
+// and the angle up the arc along the wind is that of a hemisphere over the
+// same point.
+func (c RoundCase) RoofPressure(distance float64) float64 {
+	d := c.round.Diameter
+	if rise := c.round.Rise() / d; rise >= minDomeRise {
+		cos := math.Max(-1, math.Min(1, 1-2*distance/d))
+		theta := math.Acos(cos) * 180 / math.Pi
+		cp := domeCp(c.round.EaveHeight/d, rise, theta, c.caseB)
+		return c.qt*GustFactor*cp + c.internal()
+	}
+
+	h := c.round.MeanRoofHeight()
+	cp := roofZoneCp(h/d, distance/h)
+	if !c.uplift {
+		cp = -0.18
+	}
+	return c.qh*GustFactor*cp + c.internal()
+}
@@ -0,0 +1,115 @@
+package asce7
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundCases(T *testing.T) {
+	w := Wind{Speed: 115, Exposure: ExposureC, Enclosure: Enclosed}
+	r := Round{Diameter: 24, EaveHeight: 12, RoofAngle: 18.4}
+
+	cases, err := w.RoundCases(r)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if len(cases) != 4 {
+		T.Fatalf("%d cases instead of 4", len(cases))
+	}
+	uplift, pressure := cases[0], cases[1]
+	qh, _ := w.VelocityPressure(r.MeanRoofHeight())
+
+	// the wall is pushed in facing the wind and sucked out at the sides,
+	// less so for a wall shorter than its diameter
+	if p := pressure.WallPressure(0); !near(p, qh*0.85*0.85+qh*0.18, 1e-9) {
+		T.Errorf("windward wall %f", p)
+	}
+	kb := 1 + 0.55*1.25*math.Log10(0.5)
+	if p := uplift.WallPressure(90); !near(p, qh*0.85*-1.4*kb-qh*0.18, 1e-9) {
+		T.Errorf("side wall %f", p)
+	}
+	kb = 1 + 0.55*0.3*math.Log10(0.5)
+	if p := uplift.WallPressure(180); !near(p, qh*0.85*-0.45*kb-qh*0.18, 1e-9) {
+		T.Errorf("leeward wall %f", p)
+	}
+	if uplift.WallPressure(30) != uplift.WallPressure(-30) {
+		T.Errorf("wall pressures are not symmetric")
+	}
+
+	// a 4:12 cone rises 0.167 of its diameter and takes the dome
+	// coefficients of f/D = 0.2 for hD/D = 0.5 with the pressure at its top
+	if cases[2].Name != "B uplift" {
+		T.Errorf("third case is %q", cases[2].Name)
+	}
+	qt, _ := w.VelocityPressure(r.EaveHeight + r.Rise())
+	for _, c := range []struct {
+		distance, cp float64
+	}{{0, -0.6}, {12, -1.0}, {24, -0.6}} {
+		if p := uplift.RoofPressure(c.distance); !near(p, qt*0.85*c.cp-qh*0.18, 1e-9) {
+			T.Errorf("roof %f ft from the windward edge %f instead of Cp %f", c.distance, p, c.cp)
+		}
+	}
+
+	// a hemisphere on walls a quarter of its diameter high, and a cone
+	// halfway between it and an f/D of 0.2
+	for _, c := range []struct {
+		rise, a, b, cc float64
+	}{{0.5, 0.6, -1.4, -0.4}, {0.35, 0.2, -1.1, -0.45}} {
+		if cp := domeCp(0.25, c.rise, 0, false); !near(cp, c.a, 1e-9) {
+			T.Errorf("Cp at A of f/D %f is %f instead of %f", c.rise, cp, c.a)
+		}
+		if cp := domeCp(0.25, c.rise, 90, false); !near(cp, c.b, 1e-9) {
+			T.Errorf("Cp at B of f/D %f is %f instead of %f", c.rise, cp, c.b)
+		}
+		if cp := domeCp(0.25, c.rise, 180, false); !near(cp, c.cc, 1e-9) {
+			T.Errorf("Cp at C of f/D %f is %f instead of %f", c.rise, cp, c.cc)
+		}
+		// case A is linear from A to B, case B holds A to 25 degrees
+		if cp := domeCp(0.25, c.rise, 45, false); !near(cp, (c.a+c.b)/2, 1e-9) {
+			T.Errorf("case A Cp at 45 degrees of f/D %f is %f", c.rise, cp)
+		}
+		if cp := domeCp(0.25, c.rise, 25, true); !near(cp, c.a, 1e-9) {
+			T.Errorf("case B Cp at 25 degrees of f/D %f is %f", c.rise, cp)
+		}
+		if cp := domeCp(0.25, c.rise, 57.5, true); !near(cp, (c.a+c.b)/2, 1e-9) {
+			T.Errorf("case B Cp at 57.5 degrees of f/D %f is %f", c.rise, cp)
+		}
+	}
+
+	// 45 degrees up the arc is 0.146 of the diameter in from the edge
+	hemi := Round{Diameter: 24, EaveHeight: 6, RoofAngle: 45}
+	hc, err := w.RoundCases(hemi)
+	if err != nil {
+		T.Fatal(err)
+	}
+	qt, _ = w.VelocityPressure(18)
+	qh, _ = w.VelocityPressure(hemi.MeanRoofHeight())
+	if p := hc[0].RoofPressure(12 * (1 - math.Sqrt2/2)); !near(p, qt*0.85*(0.6-1.4)/2-qh*0.18, 1e-9) {
+		T.Errorf("hemisphere 45 degrees up the arc %f", p)
+	}
+
+	// nearly flat cones are flat roofs
+	flat := Round{Diameter: 24, EaveHeight: 12, RoofAngle: 2}
+	fc, err := w.RoundCases(flat)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if len(fc) != 2 {
+		T.Fatalf("%d cases of a flat roof instead of 2", len(fc))
+	}
+	qf, _ := w.VelocityPressure(flat.MeanRoofHeight())
+	ratio := flat.MeanRoofHeight() / flat.Diameter
+	if p := fc[0].RoofPressure(6); !near(p, qf*0.85*roofZoneCp(ratio, 6/flat.MeanRoofHeight())-qf*0.18, 1e-9) {
+		T.Errorf("flat roof %f", p)
+	}
+	if p := fc[1].RoofPressure(6); !near(p, qf*0.85*-0.18+qf*0.18, 1e-9) {
+		T.Errorf("flat roof under pressure %f", p)
+	}
+
+	if _, err := w.RoundCases(Round{Diameter: 24, EaveHeight: 12, RoofAngle: 50}); err == nil {
+		T.Errorf("roof steeper than a hemisphere accepted")
+	}
+	if _, err := (Wind{Exposure: ExposureC, Enclosure: Enclosed}).RoundCases(r); err == nil {
+		T.Errorf("zero wind speed accepted")
+	}
+}
@@ -0,0 +1,205 @@
+package asce7
+
+import (
+	"fmt"
+	"math"
+)
+
+// SiteClass of the soil at the site, chapter 20
+type SiteClass string
+
+const (
+	SiteClassA SiteClass = "A"
+	SiteClassB SiteClass = "B"
+	SiteClassC SiteClass = "C"
+	SiteClassD SiteClass = "D"
+	SiteClassE SiteClass = "E"
+)
+
+// site coefficient tables 11.4-1 and 11.4-2 at SS of 0.25 to 1.5 and S1 of
+// 0.1 to 0.6.  Site class E has no coefficient for strong shaking, which needs
+// a site response analysis.
+var (
+	ssSteps = []float64{0.25, 0.5, 0.75, 1.0, 1.25, 1.5}
+	s1Steps = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6}
+	faTable = map[SiteClass][]float64{
+		SiteClassA: {0.8, 0.8, 0.8, 0.8, 0.8, 0.8},
+		SiteClassB: {0.9, 0.9, 0.9, 0.9, 0.9, 0.9},
+		SiteClassC: {1.3, 1.3, 1.2, 1.2, 1.2, 1.2},
+		SiteClassD: {1.6, 1.4, 1.2, 1.1, 1.0, 1.0},
+		SiteClassE: {2.4, 1.7, 1.3},
+	}
+	fvTable = map[SiteClass][]float64{
+		SiteClassA: {0.8, 0.8, 0.8, 0.8, 0.8, 0.8},
+		SiteClassB: {0.8, 0.8, 0.8, 0.8, 0.8, 0.8},
+		SiteClassC: {1.5, 1.5, 1.5, 1.5, 1.5, 1.4},
+		SiteClassD: {2.4, 2.2, 2.0, 1.9, 1.8, 1.7},
+		SiteClassE: {4.2, 3.3, 2.8, 2.4, 2.2, 2.0},
+	}
+)
+
+// Seismic is the site and structural system data for the equivalent lateral
+// force procedure of 12.8
+type Seismic struct {
+	// SS and S1 are the mapped risk targeted spectral accelerations in g at
+	// short periods and at 1 s
+	SS, S1 float64
+	// SiteClass defaults to D, with Fa at least 1.2, when the soil is unknown
+	SiteClass SiteClass
+	// R is the response modification coefficient of the seismic force
+	// resisting system, table 12.2-1
+	R float64
+	// Ie is the importance factor of table 1.5-2, defaults to 1.0 for risk
+	// category II buildings
+	Ie float64
+	// TL is the long period transition period in s, defaults to 8 s
+	TL float64
+	// Ct and X give the approximate period, defaulting to 0.02 and 0.75 for
+	// all other structural systems in table 12.8-2
+	Ct, X float64
+}
+
+func (s Seismic) ie() float64 {
+	if s.Ie == 0 {
+		return 1
+	}
+	return s.Ie
+}
+
+func (s Seismic) tl() float64 {
+	if s.TL == 0 {
+		return 8
+	}
+	return s.TL
+}
+
+func (s Seismic) siteClass() SiteClass {
+	if s.SiteClass == "" {
+		return SiteClassD
+	}
+	return s.SiteClass
+}
+
+// siteCoefficient interpolates a site coefficient table at x
+func siteCoefficient(table map[SiteClass][]float64, steps []float64, class SiteClass, x float64) (float64, error) {
+	row, ok := table[class]
+	if !ok {
+		return 0, fmt.Errorf("site class %q needs a site response analysis", class)
+	}
+	if x > steps[len(row)-1] && len(row) < len(steps) {
+		return 0, fmt.Errorf("site class %q needs a site response analysis at %.2fg", class, x)
+	}
+	return interpolate(x, steps[:len(row)], row), nil
+}
+
+// Fa is the short period site coefficient, table 11.4-1
+func (s Seismic) Fa() (float64, error) {
+	fa, err := siteCoefficient(faTable, ssSteps, s.siteClass(), s.SS)
+	if err != nil {
+		return 0, err
+	}
+	if s.SiteClass == "" {
+		fa = math.Max(fa, 1.2)
+	}
+	return fa, nil
+}
+
+// Fv is the long period site coefficient, table 11.4-2
+func (s Seismic) Fv() (float64, error) {
+	return siteCoefficient(fvTable, s1Steps, s.siteClass(), s.S1)
+}
+
+// SDS is the design spectral acceleration at short periods, equation 11.4-3
+func (s Seismic) SDS() (float64, error) {
+	fa, err := s.Fa()
+	return 2. / 3. * fa * s.SS, err
+}
+
+// SD1 is the design spectral acceleration at 1 s, equation 11.4-4
+func (s Seismic) SD1() (float64, error) {
+	fv, err := s.Fv()
+	return 2. / 3. * fv * s.S1, err
+}
+
+// Period is the approximate fundamental period Ta in s of a structure hn ft
+// tall, equation 12.8-7
+func (s Seismic) Period(hn float64) float64 {
+	ct, x := s.Ct, s.X
+	if ct == 0 {
+		ct, x = 0.02, 0.75
+	}
+	return ct * math.Pow(hn, x)
+}
+
+// Cs is the seismic response coefficient at period T, 12.8.1.1
+func (s Seismic) Cs(T float64) (float64, error) {
+	if s.R <= 0 {
+		return 0, fmt.Errorf("response modification coefficient R must be positive")
+	}
+	sds, err := s.SDS()
+	if err != nil {
+		return 0, err
+	}
+	sd1, err := s.SD1()
+	if err != nil {
+		return 0, err
+	}
+	rie := s.R / s.ie()
+
+	cs := sds / rie
+	if T > 0 && T <= s.tl() {
+		cs = math.Min(cs, sd1/(T*rie))
+	} else if T > s.tl() {
+		cs = math.Min(cs, sd1*s.tl()/(T*T*rie))
+	}
+	cs = math.Max(cs, math.Max(0.044*sds*s.ie(), 0.01))
+	if s.S1 >= 0.6 {
+		cs = math.Max(cs, 0.5*s.S1/rie)
+	}
+	return cs, nil
+}
+
+// BaseShear is V for a seismic weight W on a structure hn ft tall, equation
+// 12.8-1
+func (s Seismic) BaseShear(W, hn float64) (float64, error) {
+	cs, err := s.Cs(s.Period(hn))
+	return cs * W, err
+}
+
+// Forces distributes the base shear of the weights at heights above the base
+// over the height of the structure, 12.8.3.  Each weight gets its share
+// wx hx^k / sum(wi hi^k) of the base shear.
+func (s Seismic) Forces(weights, heights []float64) ([]float64, error) {
+	if len(weights) != len(heights) {
+		return nil, fmt.Errorf("%d weights at %d heights", len(weights), len(heights))
+	}
+	var W, hn float64
+	for i, w := range weights {
+		W += w
+		hn = math.Max(hn, heights[i])
+	}
+	if hn <= 0 {
+		return nil, fmt.Errorf("structure has no height above its base")
+	}
+	T := s.Period(hn)
+	V, err := s.BaseShear(W, hn)
+	if err != nil {
+		return nil, err
+	}
+
+	// the distribution exponent grows from 1 to 2 with the period
+	k := interpolate(T, []float64{0.5, 2.5}, []float64{1, 2})
+	wh := make([]float64, len(weights))
+	sum := 0.
+	for i, w := range weights {
+		if heights[i] > 0 {
+			wh[i] = w * math.Pow(heights[i], k)
+		}
+		sum += wh[i]
+	}
+	ret := make([]float64, len(weights))
+	for i := range ret {
+		ret[i] = V * wh[i] / sum
+	}
+	return ret, nil
+}
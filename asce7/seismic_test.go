@@ -0,0 +1,64 @@
+package asce7
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSiteCoefficients(T *testing.T) {
+	s := Seismic{SS: 1.0, S1: 0.4, SiteClass: SiteClassC}
+	if fa, _ := s.Fa(); fa != 1.2 {
+		T.Errorf("Fa is %f instead of 1.2", fa)
+	}
+	if fv, _ := s.Fv(); fv != 1.5 {
+		T.Errorf("Fv is %f instead of 1.5", fv)
+	}
+
+	// the default site class D has an Fa of at least 1.2
+	s = Seismic{SS: 1.25, S1: 0.15}
+	if fa, _ := s.Fa(); fa != 1.2 {
+		T.Errorf("default Fa is %f instead of 1.2", fa)
+	}
+	if fv, _ := s.Fv(); !near(fv, 2.3, 1e-9) {
+		T.Errorf("default Fv is %f instead of 2.3", fv)
+	}
+
+	if _, err := (Seismic{SS: 1.0, SiteClass: SiteClassE}).Fa(); err == nil {
+		T.Errorf("site class E accepted for strong shaking")
+	}
+}
+
+func TestSeismicForces(T *testing.T) {
+	s := Seismic{SS: 1.5, S1: 0.6, SiteClass: SiteClassD, R: 6.5}
+	sds, _ := s.SDS()
+	sd1, _ := s.SD1()
+	if !near(sds, 1.0, 1e-9) || !near(sd1, 2./3*1.7*0.6, 1e-9) {
+		T.Errorf("SDS %f SD1 %f", sds, sd1)
+	}
+
+	// a short building is on the plateau of the spectrum
+	T0 := s.Period(15)
+	if !near(T0, 0.02*math.Pow(15, 0.75), 1e-9) {
+		T.Errorf("period %f", T0)
+	}
+	V, err := s.BaseShear(100, 15)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if !near(V, 100/6.5, 1e-9) {
+		T.Errorf("base shear %f instead of %f", V, 100/6.5)
+	}
+
+	// with k = 1 the forces follow the weight times height
+	f, err := s.Forces([]float64{10, 50, 40}, []float64{0, 10, 15})
+	if err != nil {
+		T.Fatal(err)
+	}
+	if f[0] != 0 || !near(f[1]+f[2], V, 1e-9) || !near(f[2]/f[1], 600./500, 1e-9) {
+		T.Errorf("forces %v of %f", f, V)
+	}
+
+	if _, err := (Seismic{SS: 1.5, S1: 0.6}).Cs(0.2); err == nil {
+		T.Errorf("missing R accepted")
+	}
+}
@@ -0,0 +1,202 @@
+package asce7
+
+import (
+	"fmt"
+	"math"
+)
+
+// RoofExposure is how exposed the roof is to wind scouring snow off of it, the
+// columns of table 7.3-1
+type RoofExposure string
+
+const (
+	// roofs without shelter from terrain, trees or taller structures
+	FullyExposed     RoofExposure = "fully exposed"
+	PartiallyExposed RoofExposure = "partially exposed"
+	// roofs tightly surrounded by conifers or taller structures
+	Sheltered RoofExposure = "sheltered"
+)
+
+// RoofSurface of the roof for the slope factor, 7.4
+type RoofSurface int
+
+const (
+	// Nonslippery roofs such as asphalt shingles and wood shakes
+	Nonslippery RoofSurface = iota
+	// Slippery unobstructed roofs such as metal, slate and glass that snow
+	// can slide clear of
+	Slippery
+)
+
+// Snow is the site snow data for the roof snow loads of chapter 7
+type Snow struct {
+	// Ground is the ground snow load pg in psf
+	Ground       float64
+	Exposure     Exposure
+	RoofExposure RoofExposure
+	// Ct is the thermal factor of table 7.3-2, defaults to 1.0 for heated
+	// buildings.  Unheated buildings are 1.2.
+	Ct float64
+	// Is is the importance factor of table 1.5-2, defaults to 1.0 for risk
+	// category II buildings
+	Is      float64
+	Surface RoofSurface
+}
+
+func (s Snow) ct() float64 {
+	if s.Ct == 0 {
+		return 1
+	}
+	return s.Ct
+}
+
+func (s Snow) is() float64 {
+	if s.Is == 0 {
+		return 1
+	}
+	return s.Is
+}
+
+// Ce is the exposure factor of table 7.3-1
+func (s Snow) Ce() (float64, error) {
+	var col int
+	switch s.RoofExposure {
+	case FullyExposed:
+		col = 0
+	case PartiallyExposed:
+		col = 1
+	case Sheltered:
+		col = 2
+	default:
+		return 0, fmt.Errorf("unknown roof exposure %q", s.RoofExposure)
+	}
+	switch s.Exposure {
+	case ExposureB:
+		return []float64{0.9, 1.0, 1.2}[col], nil
+	case ExposureC:
+		return []float64{0.9, 1.0, 1.1}[col], nil
+	case ExposureD:
+		return []float64{0.8, 0.9, 1.0}[col], nil
+	}
+	return 0, fmt.Errorf("unknown exposure category %q", s.Exposure)
+}
+
+// FlatRoof is the flat roof snow load pf in psf, equation 7.3-1
+func (s Snow) FlatRoof() (float64, error) {
+	ce, err := s.Ce()
+	if err != nil {
+		return 0, err
+	}
+	return 0.7 * ce * s.ct() * s.is() * s.Ground, nil
+}
+
+// Minimum is the minimum snow load pm of low slope roofs in psf, 7.3.4
+func (s Snow) Minimum() float64 {
+	if s.Ground <= 20 {
+		return s.is() * s.Ground
+	}
+	return 20 * s.is()
+}
+
+// SlopeFactor is Cs of a roof sloped angle degrees, figure 7.4-1.  The
+// factor is 1 up to a slope that depends on the thermal factor and surface
+// and falls linearly to 0 at 70 degrees.
+func (s Snow) SlopeFactor(angle float64) float64 {
+	var flat float64
+	switch ct := s.ct(); {
+	case ct <= 1.0:
+		flat = 30
+		if s.Surface == Slippery {
+			flat = 5
+		}
+	case ct <= 1.1:
+		flat = 37.5
+		if s.Surface == Slippery {
+			flat = 10
+		}
+	default:
+		flat = 45
+		if s.Surface == Slippery {
+			flat = 15
+		}
+	}
+	switch {
+	case angle <= flat:
+		return 1
+	case angle >= 70:
+		return 0
+	}
+	return (70 - angle) / (70 - flat)
+}
+
+// Density is the unit weight of snow gamma in pcf for ground snow load pg,
+// equation 7.7-1
+func Density(pg float64) float64 {
+	return math.Min(0.13*pg+14, 30)
+}
+
+// DriftHeight is the height hd in ft of a drift formed by wind blowing snow
+// over a fetch of lu ft, figure 7.6-1.  Fetches under 20 ft use 20 ft.
+func DriftHeight(pg, lu float64) float64 {
+	lu = math.Max(lu, 20)
+	return math.Max(0, 0.43*math.Cbrt(lu)*math.Pow(pg+10, 0.25)-1.5)
+}
+
+// RoofSnow is the design snow on a gable or conical roof in psf of horizontal
+// projection
+type RoofSnow struct {
+	// Balanced is the sloped roof snow load ps
+	Balanced float64
+	// Unbalanced is false for roofs too flat or too steep to collect drifts
+	Unbalanced bool
+	// Windward and Leeward are the uniform unbalanced loads on each side of
+	// the ridge
+	Windward, Leeward float64
+	// Drift is the surcharge added to Leeward within DriftWidth ft of the
+	// ridge, measured horizontally.  Narrow roofs have no drift.
+	Drift, DriftWidth float64
+}
+
+// Roof returns the balanced and unbalanced snow on the building's roof,
+// 7.4 and 7.6.1.  The horizontal distance from eave to ridge is half the
+// width.
+func (s Snow) Roof(b Building) (RoofSnow, error) {
+	if s.Ground <= 0 {
+		return RoofSnow{}, fmt.Errorf("ground snow load must be positive")
+	}
+	if err := b.validate(); err != nil {
+		return RoofSnow{}, err
+	}
+	pf, err := s.FlatRoof()
+	if err != nil {
+		return RoofSnow{}, err
+	}
+
+	r := RoofSnow{Balanced: s.SlopeFactor(b.RoofAngle) * pf}
+	if b.RoofAngle < 15 {
+		r.Balanced = math.Max(r.Balanced, s.Minimum())
+	}
+
+	// unbalanced loads are only required from 1/2 on 12 to 7 on 12
+	slope := math.Tan(b.RoofAngle * math.Pi / 180)
+	if slope < 0.5/12 || slope > 7/12.+1e-9 {
+		return r, nil
+	}
+	r.Unbalanced = true
+
+	w := b.Width / 2
+	if w <= 20 {
+		r.Leeward = s.is() * s.Ground
+		return r, nil
+	}
+	r.Windward = 0.3 * r.Balanced
+	r.Leeward = r.Balanced
+
+	// the drift is spread over a length that depends on the run S per unit
+	// rise of the roof
+	S := 1 / slope
+	hd := DriftHeight(s.Ground, w)
+	r.Drift = hd * Density(s.Ground) / math.Sqrt(S)
+	r.DriftWidth = math.Min(8*hd*math.Sqrt(S)/3, w)
+	return r, nil
+}
@@ -0,0 +1,74 @@
+package asce7
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSlopeFactor(T *testing.T) {
+	for _, c := range []struct {
+		s     Snow
+		angle float64
+		cs    float64
+	}{
+		{Snow{}, 30, 1},
+		{Snow{}, 45, 0.625},
+		{Snow{}, 75, 0},
+		{Snow{Surface: Slippery}, 30, 40 / 65.},
+		{Snow{Ct: 1.2}, 45, 1},
+		{Snow{Ct: 1.1, Surface: Slippery}, 40, 0.5},
+	} {
+		if cs := c.s.SlopeFactor(c.angle); !near(cs, c.cs, 1e-9) {
+			T.Errorf("Cs at %f degrees with %+v is %f instead of %f", c.angle, c.s, cs, c.cs)
+		}
+	}
+}
+
+func TestRoofSnow(T *testing.T) {
+	s := Snow{Ground: 30, Exposure: ExposureC, RoofExposure: PartiallyExposed}
+	if pf, err := s.FlatRoof(); err != nil {
+		T.Fatal(err)
+	} else if !near(pf, 21, 1e-9) {
+		T.Errorf("pf is %f instead of 21", pf)
+	}
+
+	// a narrow roof loads the leeward side with the ground snow
+	r, err := s.Roof(Building{Width: 24, Length: 40, EaveHeight: 10, RoofAngle: 30})
+	if err != nil {
+		T.Fatal(err)
+	}
+	if !r.Unbalanced || r.Windward != 0 || r.Leeward != 30 || r.Drift != 0 {
+		T.Errorf("narrow roof snow %+v", r)
+	}
+
+	// a wide 4 on 12 roof has a drift at the ridge
+	angle := math.Atan(4/12.) * 180 / math.Pi
+	r, err = s.Roof(Building{Width: 60, Length: 40, EaveHeight: 10, RoofAngle: angle})
+	if err != nil {
+		T.Fatal(err)
+	}
+	hd := 0.43*math.Cbrt(30)*math.Pow(40, 0.25) - 1.5
+	if !near(r.Balanced, 21, 1e-9) || !near(r.Windward, 6.3, 1e-9) || !near(r.Leeward, 21, 1e-9) {
+		T.Errorf("wide roof snow %+v", r)
+	}
+	if !near(r.Drift, hd*17.9/math.Sqrt(3), 1e-9) || !near(r.DriftWidth, 8*hd*math.Sqrt(3)/3, 1e-9) {
+		T.Errorf("drift %f over %f ft", r.Drift, r.DriftWidth)
+	}
+
+	// steep and nearly flat roofs have no unbalanced load but flat roofs
+	// carry the minimum
+	if r, _ := s.Roof(Building{Width: 24, Length: 40, EaveHeight: 10, RoofAngle: 45}); r.Unbalanced {
+		T.Errorf("12 on 12 roof has unbalanced snow")
+	}
+	r, _ = s.Roof(Building{Width: 24, Length: 40, EaveHeight: 10, RoofAngle: 1})
+	if r.Unbalanced || r.Balanced != 21 {
+		T.Errorf("flat roof snow %+v", r)
+	}
+	if r, _ := (Snow{Ground: 10, Exposure: ExposureC, RoofExposure: FullyExposed}).Roof(Building{Width: 24, Length: 40, EaveHeight: 10}); r.Balanced != 10 {
+		T.Errorf("minimum roof snow %f instead of 10", r.Balanced)
+	}
+
+	if _, err := (Snow{Ground: 30, Exposure: ExposureC}).Roof(Building{Width: 24, Length: 40, EaveHeight: 10}); err == nil {
+		T.Errorf("missing roof exposure accepted")
+	}
+}
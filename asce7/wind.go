@@ -0,0 +1,437 @@
+// Package asce7 computes environmental design loads following ASCE 7-16 for
+// the simple buildings generated by the frames package.
+//
+// Like the standard itself the package works in US customary units: wind
+// speeds in mph, lengths and heights in ft, angles in degrees and pressures in
+// psf.  Spectral accelerations are in g and periods in s, and seismic forces
+// come out in the units of the weights.
+package asce7
+
+import (
+	"fmt"
+	"math"
+)
+
+// Exposure category of the terrain upwind of the building, 26.7
+type Exposure string
+
+const (
+	// urban, suburban and wooded areas
+	ExposureB Exposure = "B"
+	// open terrain with scattered obstructions
+	ExposureC Exposure = "C"
+	// flat unobstructed areas and water surfaces
+	ExposureD Exposure = "D"
+)
+
+// terrain returns the power law exponent alpha and gradient height zg of
+// table 26.11-1
+func (e Exposure) terrain() (alpha, zg float64, err error) {
+	switch e {
+	case ExposureB:
+		return 7.0, 1200, nil
+	case ExposureC:
+		return 9.5, 900, nil
+	case ExposureD:
+		return 11.5, 700, nil
+	}
+	return 0, 0, fmt.Errorf("unknown exposure category %q", e)
+}
+
+// Kz is the velocity pressure exposure coefficient at height z, table
+// 26.10-1
+func (e Exposure) Kz(z float64) (float64, error) {
+	alpha, zg, err := e.terrain()
+	if err != nil {
+		return 0, err
+	}
+	z = math.Max(z, 15)
+	z = math.Min(z, zg)
+	return 2.01 * math.Pow(z/zg, 2/alpha), nil
+}
+
+// Enclosure classification of the building, 26.2
+type Enclosure string
+
+const (
+	Enclosed          Enclosure = "enclosed"
+	PartiallyEnclosed Enclosure = "partially enclosed"
+	Open              Enclosure = "open"
+)
+
+// GCpi is the magnitude of the internal pressure coefficient, table 26.13-1
+func (e Enclosure) GCpi() (float64, error) {
+	switch e {
+	case Enclosed:
+		return 0.18, nil
+	case PartiallyEnclosed:
+		return 0.55, nil
+	case Open:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unknown enclosure classification %q", e)
+}
+
+// Shape of a hill or escarpment for the topographic factor
+type Shape int
+
+const (
+	// Flat terrain has no speed up, Kzt = 1
+	Flat Shape = iota
+	Ridge2D
+	Escarpment2D
+	Hill3D
+)
+
+// Topography describes an isolated hill, ridge or escarpment near the
+// building, figure 26.8-1.  The zero value is flat terrain.
+type Topography struct {
+	Shape Shape
+	// H is the height of the hill above the upwind terrain and Lh the distance
+	// upwind of the crest to where the ground is half the height of the hill
+	H, Lh float64
+	// X is the distance from the crest to the building and Downwind is true
+	// if the building is downwind of the crest
+	X        float64
+	Downwind bool
+}
+
+// Kzt is the topographic factor at height z above the ground
+func (t Topography) Kzt(e Exposure, z float64) (float64, error) {
+	if t.Shape == Flat || t.H <= 0 || t.Lh <= 0 {
+		return 1, nil
+	}
+
+	exposure := map[Exposure]int{ExposureB: 0, ExposureC: 1, ExposureD: 2}[e]
+	var k1 [3]float64
+	var gamma, mu float64
+	switch t.Shape {
+	case Ridge2D:
+		k1, gamma, mu = [3]float64{1.30, 1.45, 1.55}, 3, 1.5
+	case Escarpment2D:
+		k1, gamma, mu = [3]float64{0.75, 0.85, 0.95}, 2.5, 1.5
+		if t.Downwind {
+			mu = 4
+		}
+	case Hill3D:
+		k1, gamma, mu = [3]float64{0.95, 1.05, 1.15}, 4, 1.5
+	default:
+		return 0, fmt.Errorf("unknown topographic shape %d", t.Shape)
+	}
+
+	ratio, lh := t.H/t.Lh, t.Lh
+	// speed up is negligible for gentle slopes
+	if ratio < 0.2 {
+		return 1, nil
+	}
+	if ratio > 0.5 {
+		ratio, lh = 0.5, 2*t.H
+	}
+
+	K1 := k1[exposure] * ratio
+	K2 := math.Max(0, 1-math.Abs(t.X)/(mu*lh))
+	K3 := math.Exp(-gamma * z / lh)
+	return math.Pow(1+K1*K2*K3, 2), nil
+}
+
+// Building is a rectangular gable building.  The ridge runs along Length and
+// the roof slopes down to eaves on both sides of Width.
+type Building struct {
+	Width      float64
+	Length     float64
+	EaveHeight float64
+	// RoofAngle is the slope of the roof from horizontal in degrees
+	RoofAngle float64
+}
+
+// MeanRoofHeight is h in the standard, the eave height for roofs flatter than
+// 10 degrees
+func (b Building) MeanRoofHeight() float64 {
+	if b.RoofAngle <= 10 {
+		return b.EaveHeight
+	}
+	return b.EaveHeight + b.Width/4*math.Tan(b.RoofAngle*math.Pi/180)
+}
+
+func (b Building) validate() error {
+	if b.Width <= 0 || b.Length <= 0 || b.EaveHeight <= 0 {
+		return fmt.Errorf("building dimensions must be positive")
+	}
+	if b.RoofAngle < 0 || b.RoofAngle >= 90 {
+		return fmt.Errorf("roof angle %f is not between 0 and 90 degrees", b.RoofAngle)
+	}
+	return nil
+}
+
+// Wind is the site wind data for the directional procedure of chapter 27
+type Wind struct {
+	// Speed is the basic wind speed V in mph
+	Speed      float64
+	Exposure   Exposure
+	Topography Topography
+	Enclosure  Enclosure
+	// Kd is the wind directionality factor, defaults to 0.85 for buildings
+	Kd float64
+}
+
+// GustFactor of a rigid building, 26.11.1
+const GustFactor = 0.85
+
+func (w Wind) kd() float64 {
+	if w.Kd == 0 {
+		return 0.85
+	}
+	return w.Kd
+}
+
+// VelocityPressure is qz at height z in psf, equation 26.10-1 with the ground
+// elevation factor Ke taken as 1
+func (w Wind) VelocityPressure(z float64) (float64, error) {
+	kz, err := w.Exposure.Kz(z)
+	if err != nil {
+		return 0, err
+	}
+	kzt, err := w.Topography.Kzt(w.Exposure, z)
+	if err != nil {
+		return 0, err
+	}
+	return 0.00256 * kz * kzt * w.kd() * w.Speed * w.Speed, nil
+}
+
+// Surface of the building relative to the wind
+type Surface int
+
+const (
+	WindwardWall Surface = iota
+	LeewardWall
+	SideWall
+)
+
+// WindDirection relative to the ridge of the building
+type WindDirection int
+
+const (
+	// Normal wind blows across the ridge onto the eave walls
+	Normal WindDirection = iota
+	// Parallel wind blows along the ridge onto the gable walls
+	Parallel
+)
+
+func (d WindDirection) String() string {
+	if d == Parallel {
+		return "parallel"
+	}
+	return "normal"
+}
+
+// WindCase is one of the load cases of the building for a wind direction.
+// Pressures are net of internal pressure and positive toward the surface.
+type WindCase struct {
+	Name      string
+	Direction WindDirection
+	// GCpi is the internal pressure coefficient of the case.  Positive
+	// internal pressure pushes out on every surface.
+	GCpi float64
+
+	wind     Wind
+	building Building
+	qh       float64
+	// the horizontal dimension of the building along and across the wind
+	along, across float64
+	// uplift selects the lower roof pressure coefficients where the standard
+	// gives two
+	uplift bool
+}
+
+// Cases returns the wind load cases of the building.  For each direction the
+// uplift case pairs the roof suction coefficients with positive internal
+// pressure and the pressure case pairs the roof pressure coefficients with
+// negative internal pressure.
+func (w Wind) Cases(b Building) ([]WindCase, error) {
+	if w.Speed <= 0 {
+		return nil, fmt.Errorf("wind speed must be positive")
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	gcpi, err := w.Enclosure.GCpi()
+	if err != nil {
+		return nil, err
+	}
+	qh, err := w.VelocityPressure(b.MeanRoofHeight())
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []WindCase
+	for _, dir := range []WindDirection{Normal, Parallel} {
+		along, across := b.Width, b.Length
+		if dir == Parallel {
+			along, across = across, along
+		}
+		for _, uplift := range []bool{true, false} {
+			c := WindCase{
+				Direction: dir,
+				GCpi:      gcpi,
+				wind:      w,
+				building:  b,
+				qh:        qh,
+				along:     along,
+				across:    across,
+				uplift:    uplift,
+			}
+			c.Name = dir.String() + " uplift"
+			if !uplift {
+				c.GCpi = -gcpi
+				c.Name = dir.String() + " pressure"
+			}
+			ret = append(ret, c)
+		}
+	}
+	return ret, nil
+}
+
+// internal is the internal pressure toward the surface
+func (c WindCase) internal() float64 {
+	return -c.qh * c.GCpi
+}
+
+// WallPressure is the net pressure on a wall at height z
+func (c WindCase) WallPressure(s Surface, z float64) (float64, error) {
+	var cp, q float64
+	switch s {
+	case WindwardWall:
+		cp = 0.8
+		var err error
+		if q, err = c.wind.VelocityPressure(z); err != nil {
+			return 0, err
+		}
+	case LeewardWall:
+		cp, q = leewardWallCp(c.along/c.across), c.qh
+	case SideWall:
+		cp, q = -0.7, c.qh
+	default:
+		return 0, fmt.Errorf("unknown wall surface %d", s)
+	}
+	return q*GustFactor*cp + c.internal(), nil
+}
+
+// RoofPressure is the net pressure on the windward or leeward slope of the
+// roof, distance from the windward edge.  Wind parallel to the ridge, or
+// normal to roofs flatter than 10 degrees, is in zones by distance from the
+// windward edge regardless of the slope.
+func (c WindCase) RoofPressure(windward bool, distance float64) float64 {
+	return c.SlopePressure(windward, distance, c.building.RoofAngle)
+}
+
+// SlopePressure is the net pressure on a plane of the roof sloped angle
+// degrees, for roofs such as gambrels with more than one slope on each side.
+// Each plane takes the coefficients of its own slope with the velocity
+// pressure at the mean height of the whole roof.
+func (c WindCase) SlopePressure(windward bool, distance, angle float64) float64 {
+	h := c.building.MeanRoofHeight()
+	ratio := h / c.along
+
+	var cp float64
+	switch {
+	case c.Direction == Parallel || angle < 10:
+		cp = roofZoneCp(ratio, distance/h)
+		if !c.uplift {
+			cp = -0.18
+		}
+	case windward:
+		lo, hi := windwardRoofCp(ratio, angle)
+		cp = hi
+		if c.uplift {
+			cp = lo
+		}
+	default:
+		cp = leewardRoofCp(ratio, angle)
+	}
+	return c.qh*GustFactor*cp + c.internal()
+}
+
+// interpolate linearly between the points (xs, ys), holding the end values
+// outside them
+func interpolate(x float64, xs, ys []float64) float64 {
+	if x <= xs[0] {
+		return ys[0]
+	}
+	for i := 1; i < len(xs); i++ {
+		if x <= xs[i] {
+			t := (x - xs[i-1]) / (xs[i] - xs[i-1])
+			return ys[i-1] + t*(ys[i]-ys[i-1])
+		}
+	}
+	return ys[len(ys)-1]
+}
+
+// leewardWallCp is from figure 27.3-1 for the ratio of the building
+// dimension along the wind to the dimension across it
+func leewardWallCp(ratio float64) float64 {
+	return interpolate(ratio, []float64{1, 2, 4}, []float64{-0.5, -0.3, -0.2})
+}
+
+// roof pressure coefficients of figure 27.3-1 for h/L of 0.25, 0.5 and 1.0
+var (
+	roofRatios = []float64{0.25, 0.5, 1.0}
+	roofAngles = []float64{10, 15, 20, 25, 30, 35, 45, 60}
+
+	windwardLow = [][]float64{
+		{-0.7, -0.5, -0.3, -0.2, -0.2, 0.0, 0.4, 0.6},
+		{-0.9, -0.7, -0.4, -0.3, -0.2, -0.2, 0.0, 0.6},
+		{-1.3, -1.0, -0.7, -0.5, -0.3, -0.2, 0.0, 0.6},
+	}
+	windwardHigh = [][]float64{
+		{-0.18, 0.0, 0.2, 0.3, 0.3, 0.4, 0.4, 0.6},
+		{-0.18, -0.18, 0.0, 0.2, 0.2, 0.3, 0.4, 0.6},
+		{-0.18, -0.18, -0.18, 0.0, 0.2, 0.2, 0.3, 0.6},
+	}
+	leeward = [][]float64{
+		{-0.3, -0.5, -0.6, -0.6, -0.6, -0.6, -0.6, -0.6},
+		{-0.5, -0.5, -0.6, -0.6, -0.6, -0.6, -0.6, -0.6},
+		{-0.7, -0.6, -0.6, -0.6, -0.6, -0.6, -0.6, -0.6},
+	}
+)
+
+// tableCp interpolates a table of roof coefficients in roof angle and then in
+// h/L
+func tableCp(table [][]float64, ratio, angle float64) float64 {
+	byRatio := make([]float64, len(roofRatios))
+	for i, row := range table {
+		byRatio[i] = interpolate(angle, roofAngles, row)
+	}
+	return interpolate(ratio, roofRatios, byRatio)
+}
+
+// windwardRoofCp returns both coefficients given for the windward slope.
+// Roofs steeper than 60 degrees have the single value 0.01 theta.
+func windwardRoofCp(ratio, angle float64) (lo, hi float64) {
+	if angle > 60 {
+		return 0.01 * angle, 0.01 * angle
+	}
+	return tableCp(windwardLow, ratio, angle), tableCp(windwardHigh, ratio, angle)
+}
+
+func leewardRoofCp(ratio, angle float64) float64 {
+	return tableCp(leeward, ratio, angle)
+}
+
+// roofZoneCp is the coefficient at distance d (in multiples of h) from the
+// windward edge for h/L ratio
+func roofZoneCp(ratio, d float64) float64 {
+	var low float64
+	switch {
+	case d <= 1:
+		low = -0.9
+	case d <= 2:
+		low = -0.5
+	default:
+		low = -0.3
+	}
+	high := -0.7
+	if d <= 0.5 {
+		high = -1.3
+	}
+	return interpolate(ratio, []float64{0.5, 1.0}, []float64{low, high})
+}
@@ -0,0 +1,129 @@
+package asce7
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+func TestKz(T *testing.T) {
+	// table 26.10-1
+	for _, c := range []struct {
+		e  Exposure
+		z  float64
+		kz float64
+	}{
+		{ExposureB, 0, 0.57},
+		{ExposureB, 30, 0.70},
+		{ExposureC, 15, 0.85},
+		{ExposureC, 30, 0.98},
+		{ExposureC, 60, 1.13},
+		{ExposureD, 40, 1.22},
+	} {
+		kz, err := c.e.Kz(c.z)
+		if err != nil {
+			T.Fatal(err)
+		}
+		if !near(kz, c.kz, 0.01) {
+			T.Errorf("Kz exposure %s at %f is %f instead of %f", c.e, c.z, kz, c.kz)
+		}
+	}
+	if _, err := Exposure("A").Kz(10); err == nil {
+		T.Errorf("exposure A accepted")
+	}
+}
+
+func TestKzt(T *testing.T) {
+	if k, _ := (Topography{}).Kzt(ExposureC, 10); k != 1 {
+		T.Errorf("flat terrain Kzt is %f", k)
+	}
+
+	// an escarpment 80 ft high with Lh 100 ft and the building 50 ft downwind
+	// at 30 ft has H/Lh capped at 0.5, so K1 0.425, K2 0.922 and K3 0.626
+	t := Topography{Shape: Escarpment2D, H: 80, Lh: 100, X: 50, Downwind: true}
+	k, err := t.Kzt(ExposureC, 30)
+	if err != nil {
+		T.Fatal(err)
+	}
+	// capping H/Lh makes Lh 160 ft
+	K1, K2, K3 := 0.85*0.5, 1-50/(4*160.), math.Exp(-2.5*30/160.)
+	if want := math.Pow(1+K1*K2*K3, 2); !near(k, want, 1e-9) {
+		T.Errorf("Kzt is %f instead of %f", k, want)
+	}
+}
+
+func TestWindCases(T *testing.T) {
+	w := Wind{Speed: 115, Exposure: ExposureC, Enclosure: Enclosed}
+	b := Building{Width: 24, Length: 40, EaveHeight: 10, RoofAngle: 30}
+
+	cases, err := w.Cases(b)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if len(cases) != 4 {
+		T.Fatalf("%d cases instead of 4", len(cases))
+	}
+
+	h := b.MeanRoofHeight()
+	if !near(h, 10+6*math.Tan(math.Pi/6), 1e-9) {
+		T.Errorf("mean roof height %f", h)
+	}
+	qh, _ := w.VelocityPressure(h)
+	if !near(qh, 0.00256*0.85*0.85*115*115, 0.5) {
+		T.Errorf("qh is %f", qh)
+	}
+
+	names := map[string]WindCase{}
+	for _, c := range cases {
+		names[c.Name] = c
+	}
+	uplift, pressure := names["normal uplift"], names["normal pressure"]
+	if uplift.GCpi != 0.18 || pressure.GCpi != -0.18 {
+		T.Errorf("internal pressure %f %f", uplift.GCpi, pressure.GCpi)
+	}
+
+	// h/L = 0.56 and 30 degrees is between -0.2 and -0.3, or 0.2, on the
+	// windward slope
+	cp := -0.2 - 0.1*(h/b.Width-0.5)/0.5
+	if p := uplift.RoofPressure(true, 0); !near(p, qh*0.85*cp-qh*0.18, 1e-9) {
+		T.Errorf("windward roof uplift %f", p)
+	}
+	if p := pressure.RoofPressure(true, 0); !near(p, qh*0.85*0.2+qh*0.18, 1e-9) {
+		T.Errorf("windward roof pressure %f", p)
+	}
+	if p := pressure.RoofPressure(false, 0); !near(p, qh*0.85*-0.6+qh*0.18, 1e-9) {
+		T.Errorf("leeward roof pressure %f", p)
+	}
+	// a steeper plane of the same roof is pushed harder, between 0.4 and 0.3
+	cp45 := 0.4 - 0.1*(h/b.Width-0.5)/0.5
+	if p := pressure.SlopePressure(true, 0, 45); !near(p, qh*0.85*cp45+qh*0.18, 1e-9) {
+		T.Errorf("windward 45 degree plane pressure %f", p)
+	}
+
+	// L/B = 0.6 on the leeward wall
+	if p, _ := uplift.WallPressure(LeewardWall, 0); !near(p, qh*0.85*-0.5-qh*0.18, 1e-9) {
+		T.Errorf("leeward wall %f", p)
+	}
+	q5, _ := w.VelocityPressure(5)
+	if p, _ := uplift.WallPressure(WindwardWall, 5); !near(p, q5*0.85*0.8-qh*0.18, 1e-9) {
+		T.Errorf("windward wall %f", p)
+	}
+
+	// along the ridge the roof is in zones from the windward gable
+	parallel := names["parallel uplift"]
+	near0 := parallel.RoofPressure(true, 1)
+	far := parallel.RoofPressure(false, 39)
+	if near0 >= far || near0 != parallel.RoofPressure(false, 1) {
+		T.Errorf("roof zones %f at the gable and %f far from it", near0, far)
+	}
+	if p, _ := parallel.WallPressure(SideWall, 0); !near(p, qh*0.85*-0.7-qh*0.18, 1e-9) {
+		T.Errorf("side wall %f", p)
+	}
+
+	if _, err := (Wind{Speed: 115, Exposure: ExposureC}).Cases(b); err == nil {
+		T.Errorf("missing enclosure accepted")
+	}
+}
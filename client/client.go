@@ -0,0 +1,203 @@
+// Package client sends models built with the model package to the skyciv API
+// to be solved and design checked.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/donniet/goframes/model"
+	"github.com/donniet/goframes/results"
+)
+
+const (
+	DefaultEndpoint = "https://api.skyciv.com/v3"
+
+	FunctionSessionStart = "S3D.session.start"
+	FunctionModelSet     = "S3D.model.set"
+	FunctionModelSolve   = "S3D.model.solve"
+	FunctionDesignCheck  = "S3D.member_design.check"
+
+	AnalysisLinear    = "linear"
+	AnalysisNonLinear = "nonlinear"
+)
+
+// APIError is returned when skyciv accepts the request but one of its
+// functions fails
+type APIError struct {
+	Function string
+	Status   int
+	Message  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("skyciv %s failed with status %d: %s", e.Function, e.Status, e.Message)
+}
+
+// HTTPError is returned when the skyciv endpoint responds with a non 200 status
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("skyciv responded with http status %d: %s", e.StatusCode, e.Body)
+}
+
+// FunctionResult is the outcome of a single function in a request
+type FunctionResult struct {
+	Function  string          `json:"function"`
+	Status    int             `json:"status"`
+	Message   string          `json:"msg"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	SessionId string          `json:"session_id,omitempty"`
+}
+
+func (r *FunctionResult) err() error {
+	if r.Status == 0 {
+		return nil
+	}
+	return &APIError{Function: r.Function, Status: r.Status, Message: r.Message}
+}
+
+// Response is the body skyciv returns.  Response holds the result of the last
+// function to run and Functions holds every function in order.
+type Response struct {
+	Response  FunctionResult   `json:"response"`
+	Functions []FunctionResult `json:"functions"`
+}
+
+// Result finds the result of the named function or nil if it did not run
+func (r *Response) Result(function string) *FunctionResult {
+	for i := range r.Functions {
+		if r.Functions[i].Function == function {
+			return &r.Functions[i]
+		}
+	}
+	return nil
+}
+
+// Results parses the analysis output of the solve function, tracing members
+// back to the continuous members of m
+func (r *Response) Results(m *model.Skyciv) (*results.Results, error) {
+	solve := r.Result(FunctionModelSolve)
+	if solve == nil {
+		return nil, fmt.Errorf("response has no %s result", FunctionModelSolve)
+	}
+	return results.Parse(solve.Data, m)
+}
+
+type Client struct {
+	Endpoint   string
+	Auth       model.Auth
+	HTTPClient *http.Client
+}
+
+func New(username, key string) *Client {
+	return &Client{
+		Endpoint: DefaultEndpoint,
+		Auth: model.Auth{
+			UserName: username,
+			Key:      key,
+		},
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type SolveOptions struct {
+	// AnalysisType defaults to AnalysisLinear
+	AnalysisType string
+	// DesignCode runs a member design check with the given code when set,
+	// e.g. "NDS_2018"
+	DesignCode string
+}
+
+// NewRequest wraps m in a request that starts a session, sets the model,
+// solves it and optionally design checks it
+func (c *Client) NewRequest(m *model.Skyciv, opts SolveOptions) *model.Request {
+	if opts.AnalysisType == "" {
+		opts.AnalysisType = AnalysisLinear
+	}
+	validate := true
+
+	req := &model.Request{
+		Auth: c.Auth,
+		Options: model.Options{
+			ValidateInput: &validate,
+		},
+		Functions: []model.Function{
+			{
+				Function:  FunctionSessionStart,
+				Arguments: map[string]interface{}{"keep_open": false},
+			},
+			{
+				Function:  FunctionModelSet,
+				Arguments: map[string]interface{}{"s3d_model": m},
+			},
+			{
+				Function:  FunctionModelSolve,
+				Arguments: map[string]interface{}{"analysis_type": opts.AnalysisType},
+			},
+		},
+	}
+
+	if opts.DesignCode != "" {
+		req.Functions = append(req.Functions, model.Function{
+			Function:  FunctionDesignCheck,
+			Arguments: map[string]interface{}{"design_code": opts.DesignCode},
+		})
+	}
+	return req
+}
+
+// Do sends req and returns the response.  The first failed function is
+// returned as an *APIError along with the response.
+func (c *Client) Do(ctx context.Context, req *model.Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	hres, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer hres.Body.Close()
+
+	if hres.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(hres.Body, 4096))
+		return nil, &HTTPError{StatusCode: hres.StatusCode, Body: string(b)}
+	}
+
+	res := new(Response)
+	if err := json.NewDecoder(hres.Body).Decode(res); err != nil {
+		return nil, fmt.Errorf("decoding skyciv response: %v", err)
+	}
+
+	for i := range res.Functions {
+		if err := res.Functions[i].err(); err != nil {
+			return res, err
+		}
+	}
+	return res, res.Response.err()
+}
+
+// Solve sends m to skyciv and returns the response
+func (c *Client) Solve(ctx context.Context, m *model.Skyciv, opts SolveOptions) (*Response, error) {
+	return c.Do(ctx, c.NewRequest(m, opts))
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/donniet/goframes/client/skycivtest"
+	"github.com/donniet/goframes/model"
+)
+
+func testModel() *model.Skyciv {
+	m := model.NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "American", "NDS", "Sawn Lumber", "8 x 10")
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 0, 10, 0)
+	mem.Begin().FixedSupport()
+	return m
+}
+
+func TestSolve(T *testing.T) {
+	srv := skycivtest.NewServer("user", "key")
+	defer srv.Close()
+	srv.Data[FunctionModelSolve] = map[string]interface{}{"0": map[string]interface{}{"name": "LC1"}}
+
+	c := New("user", "key")
+	c.Endpoint = srv.URL
+
+	res, err := c.Solve(context.Background(), testModel(), SolveOptions{DesignCode: "NDS_2018"})
+	if err != nil {
+		T.Fatal(err)
+	}
+
+	reqs := srv.Received()
+	if len(reqs) != 1 {
+		T.Fatalf("server received %d requests", len(reqs))
+	}
+	names := []string{FunctionSessionStart, FunctionModelSet, FunctionModelSolve, FunctionDesignCheck}
+	if len(reqs[0].Functions) != len(names) {
+		T.Fatalf("request has %d functions expected %d", len(reqs[0].Functions), len(names))
+	}
+	for i, f := range reqs[0].Functions {
+		if f.Function != names[i] {
+			T.Errorf("function %d is %s expected %s", i, f.Function, names[i])
+		}
+	}
+	if _, ok := reqs[0].Functions[1].Arguments["s3d_model"].(map[string]interface{}); !ok {
+		T.Errorf("model.set did not send a model")
+	}
+
+	solve := res.Result(FunctionModelSolve)
+	if solve == nil {
+		T.Fatalf("no solve result")
+	}
+	var data map[string]struct{ Name string }
+	if err := json.Unmarshal(solve.Data, &data); err != nil {
+		T.Fatal(err)
+	}
+	if data["0"].Name != "LC1" {
+		T.Errorf("solve data %s", solve.Data)
+	}
+}
+
+func TestAPIError(T *testing.T) {
+	srv := skycivtest.NewServer("user", "key")
+	defer srv.Close()
+	srv.Errors[FunctionModelSolve] = "model is unstable"
+
+	c := New("user", "key")
+	c.Endpoint = srv.URL
+
+	res, err := c.Solve(context.Background(), testModel(), SolveOptions{DesignCode: "NDS_2018"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		T.Fatalf("expected an APIError not %v", err)
+	}
+	if apiErr.Function != FunctionModelSolve || apiErr.Message != "model is unstable" {
+		T.Errorf("unexpected error %v", apiErr)
+	}
+	if res.Result(FunctionDesignCheck) != nil {
+		T.Errorf("design check ran after the solve failed")
+	}
+}
+
+func TestBadCredentials(T *testing.T) {
+	srv := skycivtest.NewServer("user", "key")
+	defer srv.Close()
+
+	c := New("user", "wrong")
+	c.Endpoint = srv.URL
+
+	_, err := c.Solve(context.Background(), testModel(), SolveOptions{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Function != FunctionSessionStart {
+		T.Errorf("expected session start to fail not %v", err)
+	}
+}
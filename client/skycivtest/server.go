@@ -0,0 +1,114 @@
+// Package skycivtest provides a fake skyciv API server for testing clients
+// offline, in the spirit of net/http/httptest.
+package skycivtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/donniet/goframes/model"
+)
+
+const SessionId = "skycivtest-session"
+
+type functionResult struct {
+	Function  string      `json:"function"`
+	Status    int         `json:"status"`
+	Message   string      `json:"msg"`
+	Data      interface{} `json:"data,omitempty"`
+	SessionId string      `json:"session_id,omitempty"`
+}
+
+type response struct {
+	Response  functionResult   `json:"response"`
+	Functions []functionResult `json:"functions"`
+}
+
+// Server answers skyciv requests.  Functions named in Data respond with the
+// given data and functions named in Errors fail with the given message, which
+// stops the rest of the request like skyciv does.  Every request received is
+// recorded in Requests.
+type Server struct {
+	*httptest.Server
+
+	UserName string
+	Key      string
+
+	mu       sync.Mutex
+	Data     map[string]interface{}
+	Errors   map[string]string
+	Requests []*model.Request
+}
+
+// NewServer starts a server that accepts the given credentials
+func NewServer(username, key string) *Server {
+	s := &Server{
+		UserName: username,
+		Key:      key,
+		Data:     make(map[string]interface{}),
+		Errors:   make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Received returns a copy of the requests received so far
+func (s *Server) Received() []*model.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*model.Request(nil), s.Requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := new(model.Request)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Requests = append(s.Requests, req)
+
+	res := &response{}
+	for _, f := range req.Functions {
+		fr := s.call(req, f)
+		res.Functions = append(res.Functions, fr)
+		res.Response = fr
+		if fr.Status != 0 {
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (s *Server) call(req *model.Request, f model.Function) functionResult {
+	fr := functionResult{Function: f.Function, SessionId: SessionId}
+
+	if req.Auth.UserName != s.UserName || req.Auth.Key != s.Key {
+		fr.Status = 1
+		fr.Message = "invalid username or key"
+		return fr
+	}
+	if msg, ok := s.Errors[f.Function]; ok {
+		fr.Status = 1
+		fr.Message = msg
+		return fr
+	}
+
+	fr.Message = "success"
+	fr.Data = s.Data[f.Function]
+	if fr.Data == nil && f.Function == "S3D.session.start" {
+		fr.Data = SessionId
+	}
+	return fr
+}
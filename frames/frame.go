@@ -0,0 +1,213 @@
+package frames
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/donniet/goframes/asce7"
+	"github.com/donniet/goframes/model"
+)
+
+// Frame is a generator of a skyciv model of a structure
+type Frame interface {
+	// Build generates the model of the frame from the named material
+	Build(materialName string) error
+	// Model is the model made by the last Build
+	Model() *model.Skyciv
+	// Settings are the materials, loads and combinations of the frame
+	Settings() *Design
+	// Parameters describe the settings of the frame that can be changed
+	Parameters() []Parameter
+}
+
+// Design are the materials, loads and load combinations every frame is built
+// and checked with
+type Design struct {
+	MaterialFile *model.MaterialFile
+	// Combinations is the name of the model.CombinationSets to check, defaults
+	// to model.CombinationsASCE7LRFD
+	Combinations string
+
+	// RoofLiveLoad and RoofDeadLoad are in ksf of roof
+	RoofLiveLoad float64
+	RoofDeadLoad float64
+	// Snow is the site snow for ASCE 7 snow loads, which are left out if the
+	// ground snow load is zero
+	Snow asce7.Snow
+	// Wind is the site wind for ASCE 7 wind loads, which are left out if the
+	// speed is zero
+	Wind asce7.Wind
+	// Seismic is the site and system data for ASCE 7 seismic loads, which
+	// are left out if SS is zero
+	Seismic asce7.Seismic
+}
+
+func (d *Design) Settings() *Design {
+	return d
+}
+
+// defaultDesign has 20 psf roof live and dead loads on a heated building in a
+// snowy, windy site
+func defaultDesign() Design {
+	return Design{
+		RoofLiveLoad: 0.02,
+		RoofDeadLoad: 0.02,
+		Snow: asce7.Snow{
+			Ground:       60,
+			Exposure:     asce7.ExposureC,
+			RoofExposure: asce7.PartiallyExposed,
+		},
+		Wind: asce7.Wind{
+			Speed:     120,
+			Exposure:  asce7.ExposureC,
+			Enclosure: asce7.Enclosed,
+		},
+	}
+}
+
+// Parameter is a setting of a frame.  Value points at the float64, int,
+// string, bool or []float64 field that holds it, or is a *psf pointing at
+// an area load in ksf.
+type Parameter struct {
+	Name  string
+	Usage string
+	Value interface{}
+}
+
+func (d *Design) roofParameters() []Parameter {
+	return []Parameter{
+		{"roof-live", "roof live load in psf", (*psf)(&d.RoofLiveLoad)},
+		{"roof-dead", "roof dead load in psf", (*psf)(&d.RoofDeadLoad)},
+	}
+}
+
+func (d *Design) environmentParameters() []Parameter {
+	return []Parameter{
+		{"ground-snow", "ground snow load in psf, 0 for no snow loads", &d.Snow.Ground},
+		{"wind-speed", "basic wind speed in mph, 0 for no wind loads", &d.Wind.Speed},
+	}
+}
+
+func (d *Design) seismicParameters() []Parameter {
+	return []Parameter{
+		{"ss", "mapped short period spectral acceleration in g, 0 for no seismic loads", &d.Seismic.SS},
+		{"s1", "mapped 1 s spectral acceleration in g", &d.Seismic.S1},
+		{"r", "response modification coefficient of the seismic system", &d.Seismic.R},
+	}
+}
+
+// FlagSet has a flag for each parameter of the frame, defaulting to its
+// current value
+func FlagSet(name string, f Frame, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, errorHandling)
+	for _, p := range f.Parameters() {
+		switch v := p.Value.(type) {
+		case *float64:
+			fs.Float64Var(v, p.Name, *v, p.Usage)
+		case *int:
+			fs.IntVar(v, p.Name, *v, p.Usage)
+		case *string:
+			fs.StringVar(v, p.Name, *v, p.Usage)
+		case *bool:
+			fs.BoolVar(v, p.Name, *v, p.Usage)
+		case *[]float64:
+			fs.Var((*floatList)(v), p.Name, p.Usage)
+		case *psf:
+			fs.Var(v, p.Name, p.Usage)
+		default:
+			panic(fmt.Errorf("parameter %s of %s has unsupported type %T", p.Name, name, p.Value))
+		}
+	}
+	return fs
+}
+
+// floatList is a flag of comma separated numbers
+type floatList []float64
+
+func (l *floatList) String() string {
+	if l == nil {
+		return ""
+	}
+	s := make([]string, len(*l))
+	for i, v := range *l {
+		s[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strings.Join(s, ",")
+}
+
+func (l *floatList) Set(s string) error {
+	*l = nil
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return err
+		}
+		*l = append(*l, v)
+	}
+	return nil
+}
+
+// psf is an area load held in ksf, like the loads of the model, that is set
+// and printed in psf, like the ground snow load
+type psf float64
+
+func (p *psf) String() string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(*p)*psfPerKsf, 'g', -1, 64)
+}
+
+func (p *psf) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*p = psf(v / psfPerKsf)
+	return nil
+}
+
+type generator struct {
+	description string
+	new         func() Frame
+}
+
+var generators = map[string]generator{
+	"simple":   {"timber frame of bents joined by plates", func() Frame { return NewSimpleFrame() }},
+	"yurt":     {"round building of posts under a conical roof", func() Frame { return NewYurt() }},
+	"geodesic": {"geodesic dome of struts between hubs", func() Frame { return NewGeodesic() }},
+	"gambrel":  {"barn of bents under a roof of two pitches", func() Frame { return NewGambrel() }},
+}
+
+// Register adds a frame generator under name, replacing any generator already
+// registered under it
+func Register(name, description string, new func() Frame) {
+	generators[name] = generator{description, new}
+}
+
+// New makes a frame of the named generator with its default settings
+func New(name string) (Frame, error) {
+	g, ok := generators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown frame %q, expected one of %v", name, Names())
+	}
+	return g.new(), nil
+}
+
+// Names lists the registered generators in order
+func Names() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Description describes the named generator
+func Description(name string) string {
+	return generators[name].description
+}
@@ -0,0 +1,81 @@
+package frames
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestGenerators(T *testing.T) {
+	mats := materials(T)
+	names := Names()
+	if want := []string{"gambrel", "geodesic", "simple", "yurt"}; !reflect.DeepEqual(names, want) {
+		T.Errorf("generators %v instead of %v", names, want)
+	}
+	for _, name := range names {
+		f, err := New(name)
+		if err != nil {
+			T.Fatal(err)
+		}
+		if Description(name) == "" {
+			T.Errorf("%s has no description", name)
+		}
+		f.Settings().MaterialFile = mats
+		if err := f.Build("Red Pine"); err != nil {
+			T.Errorf("%s: %v", name, err)
+			continue
+		}
+		if ds := f.Model().Validate(); len(ds) > 0 {
+			T.Errorf("%s: %v", name, ds)
+		}
+	}
+	if _, err := New("igloo"); err == nil {
+		T.Errorf("made a frame of an unknown generator")
+	}
+}
+
+func TestFlagSet(T *testing.T) {
+	f := NewSimpleFrame()
+	fs := FlagSet("simple", f, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse([]string{"-width", "16", "-bents", "4", "-truss", "king-post", "-bays", "10, 12", "-ground-snow", "0"}); err != nil {
+		T.Fatal(err)
+	}
+	if f.Width != 16 || f.Bents != 4 || f.Truss != "king-post" || f.Snow.Ground != 0 {
+		T.Errorf("flags not parsed into %+v", f)
+	}
+	if !reflect.DeepEqual(f.Bays, []float64{10, 12}) {
+		T.Errorf("bays parsed as %v", f.Bays)
+	}
+	if s := fs.Lookup("bays").Value.String(); s != "10,12" {
+		T.Errorf("bays print as %q", s)
+	}
+
+	if err := fs.Parse([]string{"-bays", "10,twelve"}); err == nil {
+		T.Errorf("parsed a bay that is not a number")
+	}
+
+	// area loads are given in psf like the ground snow and held in ksf
+	g := NewGambrel()
+	fs = FlagSet("gambrel", g, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if d := fs.Lookup("roof-live").DefValue; d != "20" {
+		T.Errorf("roof live load defaults to %s psf", d)
+	}
+	if err := fs.Parse([]string{"-roof-live", "30", "-roof-dead", "15", "-loft-live", "50", "-loft-dead", "12.5", "-ground-snow", "40"}); err != nil {
+		T.Fatal(err)
+	}
+	if g.RoofLiveLoad != 0.03 || g.RoofDeadLoad != 0.015 || g.LoftLiveLoad != 0.05 || g.LoftDeadLoad != 0.0125 {
+		T.Errorf("roof loads of %f and %f ksf and loft loads of %f and %f ksf", g.RoofLiveLoad, g.RoofDeadLoad, g.LoftLiveLoad, g.LoftDeadLoad)
+	}
+	if g.Snow.Ground != 40 {
+		T.Errorf("ground snow of %f psf", g.Snow.Ground)
+	}
+	if s := fs.Lookup("loft-dead").Value.String(); s != "12.5" {
+		T.Errorf("loft dead load prints as %q", s)
+	}
+	if err := fs.Parse([]string{"-roof-live", "twenty"}); err == nil {
+		T.Errorf("parsed a roof live load that is not a number")
+	}
+}
@@ -0,0 +1,497 @@
+package frames
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/donniet/goframes/asce7"
+	"github.com/donniet/goframes/model"
+)
+
+// Gambrel is a barn of bents with a roof of two pitches on each side, a steep
+// lower roof from the eaves to a break and a shallow upper roof from the break
+// to the ridge.  Each bent has a tie beam across the posts, purlin posts from
+// the tie beam up to the breaks and a collar tie between them.  Plates join
+// the tops of the posts and purlins join the breaks and ridge of the bents.
+type Gambrel struct {
+	m      *model.Skyciv
+	Width  float64
+	Length float64
+	// Height is the height of the eaves
+	Height float64
+	// LowerRise per LowerRun is the pitch of the lower roof and UpperRise per
+	// UpperRun the pitch of the upper roof
+	LowerRise float64
+	LowerRun  float64
+	UpperRise float64
+	UpperRun  float64
+	// Break is the horizontal distance in ft from each wall in to the break
+	// between the lower and upper roofs
+	Break     float64
+	BraceRise float64
+	// Bents is the number of bents spaced evenly over Length
+	Bents int
+	// Bays are the spacings in ft between each bent and the next, which
+	// replace Bents and Length when given
+	Bays []float64
+	// LoftHeight is the height in ft of a loft floor on the tie beams, which
+	// are at the eaves when there is no loft
+	LoftHeight float64
+	// LoftLiveLoad and LoftDeadLoad are in ksf of loft floor
+	LoftLiveLoad float64
+	LoftDeadLoad float64
+	// BaseRestraint is the restraint code of the post bases, defaults to
+	// model.RestraintFixed
+	BaseRestraint string
+
+	Design
+
+	bents []gambrelBent
+}
+
+// gambrelBent is the nodes of a bent along its roof and loft, from the -X
+// side to the +X side
+type gambrelBent struct {
+	posts  [2]*model.ContinuousMember
+	ties   [2]*model.Node
+	breaks [2]*model.Node
+	ridge  *model.Node
+	purlin [2]*model.ContinuousMember
+	// lower and upper are the rafters from the eaves to the breaks and from
+	// the breaks to the ridge
+	lower, upper [2]*model.ContinuousMember
+}
+
+// NewGambrel is a barn 30 ft wide and 36 ft long with a 20/12 lower roof
+// and a 6/12 upper roof and the default loads
+func NewGambrel() *Gambrel {
+	return &Gambrel{
+		Width:        30,
+		Length:       36,
+		Height:       10,
+		LowerRise:    20,
+		LowerRun:     12,
+		UpperRise:    6,
+		UpperRun:     12,
+		Break:        6,
+		BraceRise:    3,
+		Bents:        4,
+		LoftLiveLoad: 0.04,
+		LoftDeadLoad: 0.01,
+		Design:       defaultDesign(),
+	}
+}
+
+func (g *Gambrel) Parameters() []Parameter {
+	return append(append(append([]Parameter{
+		{"width", "width of the bents in ft", &g.Width},
+		{"length", "length of the barn in ft", &g.Length},
+		{"height", "height of the eaves in ft", &g.Height},
+		{"lower-rise", "rise of the lower roof per run", &g.LowerRise},
+		{"lower-run", "run of the lower roof", &g.LowerRun},
+		{"upper-rise", "rise of the upper roof per run", &g.UpperRise},
+		{"upper-run", "run of the upper roof", &g.UpperRun},
+		{"break", "horizontal distance in ft from the walls to the breaks of the roof", &g.Break},
+		{"brace-rise", "rise of the knee braces in ft", &g.BraceRise},
+		{"bents", "number of bents", &g.Bents},
+		{"bays", "comma separated spacings of the bents in ft, replacing bents and length", &g.Bays},
+		{"loft", "height of the loft floor in ft, 0 for no loft", &g.LoftHeight},
+		{"loft-live", "loft live load in psf", (*psf)(&g.LoftLiveLoad)},
+		{"loft-dead", "loft dead load in psf", (*psf)(&g.LoftDeadLoad)},
+		{"base", "restraint code of the post bases, empty for fixed", &g.BaseRestraint},
+	}, g.roofParameters()...), g.environmentParameters()...), g.seismicParameters()...)
+}
+
+func (g *Gambrel) Model() *model.Skyciv {
+	return g.m
+}
+
+// lowerAngle and upperAngle are the slopes of the roofs in degrees
+func (g *Gambrel) lowerAngle() float64 {
+	return math.Atan2(g.LowerRise, g.LowerRun) * 180 / math.Pi
+}
+
+func (g *Gambrel) upperAngle() float64 {
+	return math.Atan2(g.UpperRise, g.UpperRun) * 180 / math.Pi
+}
+
+// breakHeight and ridgeHeight are the heights of the top of the roof at the
+// breaks and ridge
+func (g *Gambrel) breakHeight() float64 {
+	return g.Height + g.Break*g.LowerRise/g.LowerRun
+}
+
+func (g *Gambrel) ridgeHeight() float64 {
+	return g.breakHeight() + (g.Width/2-g.Break)*g.UpperRise/g.UpperRun
+}
+
+// length is the distance from the first bent to the last
+func (g *Gambrel) length() float64 {
+	return g.bents[len(g.bents)-1].ridge.Z
+}
+
+// building describes the barn for the ASCE 7 loads as a gable roof of the
+// same ridge height, which has the mean roof height of the gambrel
+func (g *Gambrel) building() asce7.Building {
+	return asce7.Building{
+		Width:      g.Width,
+		Length:     g.length(),
+		EaveHeight: g.Height,
+		RoofAngle:  math.Atan2(g.ridgeHeight()-g.Height, g.Width/2) * 180 / math.Pi,
+	}
+}
+
+func (g *Gambrel) validate() error {
+	if g.Width <= 0 || g.Height <= 0 {
+		return fmt.Errorf("barns need a width and height")
+	}
+	if g.LowerRun <= 0 || g.UpperRun <= 0 || g.LowerRise <= g.UpperRise*g.LowerRun/g.UpperRun {
+		return fmt.Errorf("the lower roof must be steeper than the upper roof")
+	}
+	if g.UpperRise <= 0 {
+		return fmt.Errorf("the upper roof must rise to the ridge")
+	}
+	if g.Break <= 0 || g.Break >= g.Width/2 {
+		return fmt.Errorf("break %f ft is not between the walls and the ridge", g.Break)
+	}
+	if g.LoftHeight < 0 || g.LoftHeight >= g.Height {
+		return fmt.Errorf("loft %f ft is not below the eaves", g.LoftHeight)
+	}
+	return nil
+}
+
+func (g *Gambrel) Build(materialName string) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	positions, err := bentPositions(g.Bents, g.Length, g.Bays)
+	if err != nil {
+		return err
+	}
+
+	g.m = model.NewModel(g.MaterialFile)
+	g.bents = nil
+	secs, err := sawnLumber(g.m, materialName, "8 x 10", "8 x 10", "6 x 8", "8 x 8", "4 x 8", "8 x 10")
+	if err != nil {
+		return err
+	}
+	post, tie, rafter, purlinPost, brace, purlin := secs[0], secs[1], secs[2], secs[3], secs[4], secs[5]
+
+	for _, z := range positions {
+		if err := g.bent(post, tie, rafter, purlinPost, brace, z); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < len(g.bents); i++ {
+		if err := g.bay(g.bents[i-1], g.bents[i], purlin, brace); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range []struct {
+		mag   float64
+		group string
+	}{{-g.RoofDeadLoad, "dead"}, {-g.RoofLiveLoad, "roof live"}} {
+		if err := g.areaLoad(l.mag, l.group, g.roofPanels()...); err != nil {
+			return err
+		}
+	}
+
+	var liveGroups []string
+	if g.LoftHeight > 0 {
+		if err := g.areaLoad(-g.LoftDeadLoad, "dead", g.loftPanels()...); err != nil {
+			return err
+		}
+		if err := g.areaLoad(-g.LoftLiveLoad, "live", g.loftPanels()...); err != nil {
+			return err
+		}
+		liveGroups = append(liveGroups, "live")
+	}
+
+	var snowPatterns [][]string
+	if g.Snow.Ground > 0 {
+		if snowPatterns, err = g.snowLoads(); err != nil {
+			return err
+		}
+	}
+
+	var windGroups []string
+	if g.Wind.Speed > 0 {
+		if windGroups, err = g.windLoads(); err != nil {
+			return err
+		}
+	}
+
+	sw := g.m.NewSelfWeight()
+	sw.LoadGroup = "SW1"
+	sw.Y = -1
+
+	var seismicGroups []string
+	if g.Seismic.SS > 0 {
+		if seismicGroups, err = seismicLoads(g.m, g.Seismic, g.Snow, g.roofPanels(), "dead", "SW1"); err != nil {
+			return err
+		}
+	}
+
+	g.m.LoadCombinations.Mapping.DeadCases("dead", "SW1").LiveCases(liveGroups...).RoofLiveCases("roof live").
+		SnowPatternCases(snowPatterns...).WindCases(windGroups...).SeismicCases(seismicGroups...)
+	return g.m.UseCombinations(g.Combinations)
+}
+
+// bent adds the posts, rafters, tie beam, purlin posts, collar tie and braces
+// of a bent at z
+func (g *Gambrel) bent(post, tie, rafter, purlinPost, brace *model.Section, z float64) error {
+	var b gambrelBent
+	w := g.Width
+	b.ridge = g.m.NewNode(0, g.ridgeHeight(), z)
+
+	for i, x := range []float64{-w / 2, w / 2} {
+		b.posts[i] = g.m.NewContinuousMember(post, x, 0, z, x, g.Height, z)
+		supportBase(b.posts[i].Begin(), g.BaseRestraint)
+
+		b.ties[i] = b.posts[i].End()
+		if g.LoftHeight > 0 {
+			n, err := b.posts[i].Split(g.LoftHeight)
+			if err != nil {
+				return fmt.Errorf("error splitting post for tie beam: %v", err)
+			}
+			b.ties[i] = n
+		}
+
+		inside := x - math.Copysign(g.Break, x)
+		b.breaks[i] = g.m.NewNode(inside, g.breakHeight(), z)
+		b.lower[i] = g.m.NewContinuousMemberBetweenNodes(rafter, b.posts[i].End(), b.breaks[i])
+		b.upper[i] = g.m.NewContinuousMemberBetweenNodes(rafter, b.breaks[i], b.ridge)
+	}
+
+	tieBeam := g.m.NewContinuousMemberBetweenNodes(tie, b.ties[0], b.ties[1])
+	collar := g.m.NewContinuousMemberBetweenNodes(tie, b.breaks[0], b.breaks[1])
+	for i, q := range []model.Quadrant{model.QuadrantNP, model.QuadrantNN} {
+		foot, err := tieBeam.SplitAt(b.breaks[i].X, b.ties[i].Y, z)
+		if err != nil {
+			return err
+		}
+		b.purlin[i] = g.m.NewContinuousMemberBetweenNodes(purlinPost, foot, b.breaks[i])
+
+		if err := addBrace(b.posts[i], tieBeam, brace, g.BraceRise, q); err != nil {
+			return fmt.Errorf("bracing the bent at %f ft: %v", z, err)
+		}
+		if err := addBrace(b.purlin[i], collar, brace, g.BraceRise, q); err != nil {
+			return fmt.Errorf("bracing the bent at %f ft: %v", z, err)
+		}
+	}
+
+	g.bents = append(g.bents, b)
+	return nil
+}
+
+// bay joins two bents with plates along the eaves, purlins along the breaks
+// and a ridge beam, and braces the posts and purlin posts against them
+func (g *Gambrel) bay(a, b gambrelBent, purlin, brace *model.Section) error {
+	g.m.NewContinuousMemberBetweenNodes(purlin, a.ridge, b.ridge)
+	for i := 0; i < 2; i++ {
+		plate := g.m.NewContinuousMemberBetweenNodes(purlin, a.posts[i].End(), b.posts[i].End())
+		breakPurlin := g.m.NewContinuousMemberBetweenNodes(purlin, a.breaks[i], b.breaks[i])
+
+		for _, p := range []struct {
+			mem, against *model.ContinuousMember
+			quadrant     model.Quadrant
+		}{
+			{a.posts[i], plate, model.QuadrantNP},
+			{b.posts[i], plate, model.QuadrantNN},
+			{a.purlin[i], breakPurlin, model.QuadrantNP},
+			{b.purlin[i], breakPurlin, model.QuadrantNN},
+		} {
+			if err := addBrace(p.mem, p.against, brace, g.BraceRise, p.quadrant); err != nil {
+				return fmt.Errorf("bay ending at %f ft is too short to brace: %v", b.ridge.Z, err)
+			}
+		}
+	}
+	return nil
+}
+
+// lowerPanels and upperPanels list the roof panels of each bay on side 0 (-X)
+// or 1 (+X), each from the lower edge up and back
+func (g *Gambrel) lowerPanels(side int) (ret [][]*model.Node) {
+	for j := 1; j < len(g.bents); j++ {
+		a, b := g.bents[j-1], g.bents[j]
+		ret = append(ret, []*model.Node{a.posts[side].End(), a.breaks[side], b.breaks[side], b.posts[side].End()})
+	}
+	return
+}
+
+func (g *Gambrel) upperPanels(side int) (ret [][]*model.Node) {
+	for j := 1; j < len(g.bents); j++ {
+		a, b := g.bents[j-1], g.bents[j]
+		ret = append(ret, []*model.Node{a.breaks[side], a.ridge, b.ridge, b.breaks[side]})
+	}
+	return
+}
+
+func (g *Gambrel) roofPanels() [][]*model.Node {
+	var ret [][]*model.Node
+	for side := 0; side < 2; side++ {
+		ret = append(ret, g.lowerPanels(side)...)
+		ret = append(ret, g.upperPanels(side)...)
+	}
+	return ret
+}
+
+// loftPanels list the loft floor between the tie beams of each bay
+func (g *Gambrel) loftPanels() (ret [][]*model.Node) {
+	for j := 1; j < len(g.bents); j++ {
+		a, b := g.bents[j-1], g.bents[j]
+		ret = append(ret, []*model.Node{a.ties[0], a.ties[1], b.ties[1], b.ties[0]})
+	}
+	return
+}
+
+// wallPanels list the wall panels between posts on side 0 (-X) or 1 (+X)
+func (g *Gambrel) wallPanels(side int) (ret [][]*model.Node) {
+	for j := 1; j < len(g.bents); j++ {
+		a, b := g.bents[j-1].posts[side], g.bents[j].posts[side]
+		ret = append(ret, []*model.Node{a.Begin(), a.End(), b.End(), b.Begin()})
+	}
+	return
+}
+
+// gable is the end wall of a bent up to the roof
+func (b gambrelBent) gable() []*model.Node {
+	return []*model.Node{
+		b.posts[0].Begin(), b.posts[1].Begin(), b.posts[1].End(), b.breaks[1],
+		b.ridge, b.breaks[0], b.posts[0].End(),
+	}
+}
+
+func (g *Gambrel) areaLoad(mag float64, loadGroup string, panels ...[]*model.Node) error {
+	for _, nl := range panels {
+		al, err := g.m.NewAreaLoad(nl...)
+		if err != nil {
+			return err
+		}
+		al.LoadGroup = loadGroup
+		al.Direction = "Y"
+		al.Mag = mag
+	}
+	return nil
+}
+
+// snowLoads applies the ASCE 7 balanced snow to each plane of the roof with
+// the slope factor of its own pitch.  For wind across the ridge from either
+// side the unbalanced snow and the drift at the ridge are those of the
+// equivalent gable roof from the eaves to the ridge, as for the wind.  It
+// returns the snow patterns, each a list of load groups applied together.
+func (g *Gambrel) snowLoads() ([][]string, error) {
+	pf, err := g.Snow.FlatRoof()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range []struct {
+		angle  float64
+		panels [][]*model.Node
+	}{
+		{g.lowerAngle(), append(g.lowerPanels(0), g.lowerPanels(1)...)},
+		{g.upperAngle(), append(g.upperPanels(0), g.upperPanels(1)...)},
+	} {
+		if err := snowLoad(g.m, pf*g.Snow.SlopeFactor(p.angle), p.angle, "snow balanced", p.panels...); err != nil {
+			return nil, err
+		}
+	}
+	patterns := [][]string{{"snow balanced"}}
+
+	r, err := g.Snow.Roof(g.building())
+	if err != nil {
+		return nil, err
+	}
+	if !r.Unbalanced {
+		return patterns, nil
+	}
+
+	// wind blowing toward +X drifts snow onto the +X side
+	for _, d := range []struct {
+		name              string
+		windward, leeward int
+	}{{"+X", 0, 1}, {"-X", 1, 0}} {
+		unbalanced := "snow unbalanced " + d.name
+		for _, s := range []struct {
+			side int
+			p    float64
+		}{{d.windward, r.Windward}, {d.leeward, r.Leeward}} {
+			if err := snowLoad(g.m, s.p, g.lowerAngle(), unbalanced, g.lowerPanels(s.side)...); err != nil {
+				return nil, err
+			}
+			if err := snowLoad(g.m, s.p, g.upperAngle(), unbalanced, g.upperPanels(s.side)...); err != nil {
+				return nil, err
+			}
+		}
+		pattern := []string{unbalanced}
+
+		if r.Drift > 0 {
+			drift := "snow drift " + d.name
+			if err := g.driftLoad(r.Drift, r.DriftWidth, drift, d.leeward); err != nil {
+				return nil, err
+			}
+			pattern = append(pattern, drift)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// driftLoad applies a drift of p psf to side 0 (-X) or 1 (+X) of the roof
+// from the ridge down a horizontal width, which may pass the break.  The
+// rafters are split where it ends.
+func (g *Gambrel) driftLoad(p, width float64, loadGroup string, side int) error {
+	upperRun := g.Width/2 - g.Break
+	ends := make([]*model.Node, len(g.bents))
+	for i, b := range g.bents {
+		mem, distance := b.upper[side], width
+		if width > upperRun {
+			mem, distance = b.lower[side], width-upperRun
+		}
+		n, err := splitFromTop(mem, distance)
+		if err != nil {
+			return err
+		}
+		ends[i] = n
+	}
+
+	var upper, lower [][]*model.Node
+	for j := 1; j < len(g.bents); j++ {
+		a, b := g.bents[j-1], g.bents[j]
+		if width > upperRun {
+			upper = append(upper, []*model.Node{a.breaks[side], a.ridge, b.ridge, b.breaks[side]})
+			lower = append(lower, []*model.Node{ends[j-1], a.breaks[side], b.breaks[side], ends[j]})
+		} else {
+			upper = append(upper, []*model.Node{ends[j-1], a.ridge, b.ridge, ends[j]})
+		}
+	}
+	if err := snowLoad(g.m, p, g.upperAngle(), loadGroup, upper...); err != nil {
+		return err
+	}
+	return snowLoad(g.m, p, g.lowerAngle(), loadGroup, lower...)
+}
+
+// windLoads applies the ASCE 7 wind pressures to every wall and roof panel
+// for wind from each side of the barn, with each plane of the roof taking
+// the coefficients of its own pitch.  Each case and direction is its own load
+// group, which are returned.
+func (g *Gambrel) windLoads() ([]string, error) {
+	walls := map[string][][]*model.Node{
+		"-X": g.wallPanels(0),
+		"+X": g.wallPanels(1),
+		"-Z": {g.bents[0].gable()},
+		"+Z": {g.bents[len(g.bents)-1].gable()},
+	}
+	roofs := map[string][][]*model.Node{
+		"-X": append(g.lowerPanels(0), g.upperPanels(0)...),
+		"+X": append(g.lowerPanels(1), g.upperPanels(1)...),
+	}
+	return windLoads(g.m, g.Wind, g.building(), walls, roofs, func(c asce7.WindCase, windward bool, distance float64, panel []*model.Node) float64 {
+		angle := g.upperAngle()
+		if centroid(panel).Y < g.breakHeight() {
+			angle = g.lowerAngle()
+		}
+		return c.SlopePressure(windward, distance, angle)
+	})
+}
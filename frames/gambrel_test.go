@@ -0,0 +1,171 @@
+package frames
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGambrel(T *testing.T) {
+	mats := materials(T)
+	g := NewGambrel()
+	if h := g.breakHeight(); h != 20 {
+		T.Errorf("break at %f ft instead of 20", h)
+	}
+	if h := g.ridgeHeight(); h != 24.5 {
+		T.Errorf("ridge at %f ft instead of 24.5", h)
+	}
+
+	g.MaterialFile = mats
+	if err := g.Build("Red Pine"); err != nil {
+		T.Fatal(err)
+	}
+	if ds := g.Model().Validate(); len(ds) > 0 {
+		T.Error(ds)
+	}
+	if len(g.bents) != 4 || g.length() != 36 {
+		T.Errorf("%d bents over %f ft", len(g.bents), g.length())
+	}
+	for _, b := range g.bents {
+		if b.ridge.Y != 24.5 || b.breaks[0].Y != 20 || b.breaks[1].Y != 20 {
+			T.Errorf("bent at %f ft has its breaks at %f and %f and ridge at %f", b.ridge.Z, b.breaks[0].Y, b.breaks[1].Y, b.ridge.Y)
+		}
+		if b.ties[0] != b.posts[0].End() || b.ties[1] != b.posts[1].End() {
+			T.Errorf("bent at %f ft without a loft is not tied at the eaves", b.ridge.Z)
+		}
+	}
+	mapping := g.Model().LoadCombinations.Mapping
+	if len(mapping.Live) != 0 || len(mapping.Wind) == 0 || len(mapping.Snow) != 1 {
+		T.Errorf("loads mapped to %+v", mapping)
+	}
+
+	// a loft hangs the tie beams below the eaves and carries live load
+	g.LoftHeight = 8
+	if err := g.Build("Red Pine"); err != nil {
+		T.Fatal(err)
+	}
+	if ds := g.Model().Validate(); len(ds) > 0 {
+		T.Error(ds)
+	}
+	for _, b := range g.bents {
+		if b.ties[0].Y != 8 || b.ties[1].Y != 8 {
+			T.Errorf("loft of the bent at %f ft at %f and %f ft", b.ridge.Z, b.ties[0].Y, b.ties[1].Y)
+		}
+	}
+	if live := g.Model().LoadCombinations.Mapping.Live; !reflect.DeepEqual(live, []string{"live"}) {
+		T.Errorf("live load groups %v", live)
+	}
+	loft := 0
+	for _, al := range g.Model().AreaLoads {
+		if al.LoadGroup == "live" {
+			loft++
+			if al.Mag != -g.LoftLiveLoad {
+				T.Errorf("loft live load of %f ksf", al.Mag)
+			}
+		}
+	}
+	if loft != 3 {
+		T.Errorf("loft live load on %d panels instead of 3", loft)
+	}
+}
+
+func TestGambrelValidate(T *testing.T) {
+	for _, c := range []struct {
+		name   string
+		change func(g *Gambrel)
+	}{
+		{"no width", func(g *Gambrel) { g.Width = 0 }},
+		{"no height", func(g *Gambrel) { g.Height = 0 }},
+		{"shallow lower roof", func(g *Gambrel) { g.LowerRise = 6 }},
+		{"no lower run", func(g *Gambrel) { g.LowerRun = 0 }},
+		{"flat upper roof", func(g *Gambrel) { g.UpperRise = 0 }},
+		{"break at the wall", func(g *Gambrel) { g.Break = 0 }},
+		{"break past the ridge", func(g *Gambrel) { g.Break = 15 }},
+		{"loft underground", func(g *Gambrel) { g.LoftHeight = -1 }},
+		{"loft at the eaves", func(g *Gambrel) { g.LoftHeight = 10 }},
+	} {
+		g := NewGambrel()
+		c.change(g)
+		if err := g.validate(); err == nil {
+			T.Errorf("%s is valid", c.name)
+		}
+		if err := g.Build("Red Pine"); err == nil {
+			T.Errorf("%s built", c.name)
+		}
+	}
+	if err := NewGambrel().validate(); err != nil {
+		T.Error(err)
+	}
+}
+
+func TestGambrelSnow(T *testing.T) {
+	mats := materials(T)
+	for _, c := range []struct {
+		name                   string
+		width, brk             float64
+		lowerRise, upperRise   float64
+		upperDrift, lowerDrift int
+	}{
+		// 12 ft up 24 ft across, the drift ends on the upper roof
+		{"drift on the upper roof", 48, 6, 12, 4, 3, 0},
+		// 13 ft up 24 ft across with only 6 ft of upper roof, the drift
+		// passes the break
+		{"drift past the break", 48, 18, 8, 2, 3, 3},
+	} {
+		g := NewGambrel()
+		g.Width, g.Break, g.LowerRise, g.UpperRise = c.width, c.brk, c.lowerRise, c.upperRise
+		g.MaterialFile = mats
+		if err := g.Build("Red Pine"); err != nil {
+			T.Fatalf("%s: %v", c.name, err)
+		}
+		if ds := g.Model().Validate(); len(ds) > 0 {
+			T.Errorf("%s: %v", c.name, ds)
+		}
+		r, err := g.Snow.Roof(g.building())
+		if err != nil {
+			T.Fatal(err)
+		}
+		if !r.Unbalanced || r.Drift == 0 {
+			T.Fatalf("%s: the equivalent gable has no drift, %+v", c.name, r)
+		}
+		want := [][]string{{"snow balanced"}, {"snow unbalanced +X", "snow drift +X"}, {"snow unbalanced -X", "snow drift -X"}}
+		if p := g.Model().LoadCombinations.Mapping.SnowPatterns; !reflect.DeepEqual(p, want) {
+			T.Errorf("%s: snow patterns %v", c.name, p)
+		}
+
+		// wind toward +X drifts snow on the +X side down to the drift width
+		// from the ridge, on the upper and lower roofs
+		upper, lower := 0, 0
+		for _, al := range g.Model().AreaLoads {
+			if al.LoadGroup != "snow drift +X" {
+				continue
+			}
+			far, up := 0., true
+			for _, id := range al.Nodes {
+				n := g.Model().Nodes[id]
+				if n.X < 0 {
+					T.Errorf("%s: drift on the -X side at %f ft", c.name, n.X)
+				}
+				far = math.Max(far, n.X)
+				up = up && n.Y >= g.breakHeight()-1e-9
+			}
+			angle := g.lowerAngle()
+			if up {
+				upper++
+				angle = g.upperAngle()
+			} else {
+				lower++
+			}
+			if math.Abs(al.Mag+r.Drift/psfPerKsf*math.Cos(angle*math.Pi/180)) > 1e-9 {
+				T.Errorf("%s: drift of %f ksf", c.name, al.Mag)
+			}
+			// the drift ends on the lower roof when it passes the break
+			if (c.lowerDrift == 0 || !up) && math.Abs(far-r.DriftWidth) > 1e-6 {
+				T.Errorf("%s: drift ends %f ft from the ridge instead of %f", c.name, far, r.DriftWidth)
+			}
+		}
+		if upper != c.upperDrift || lower != c.lowerDrift {
+			T.Errorf("%s: drift on %d upper and %d lower panels", c.name, upper, lower)
+		}
+	}
+}
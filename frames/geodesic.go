@@ -1,28 +1,298 @@
 package frames
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/donniet/goframes/model"
 )
 
+const (
+	// maxFrequency limits the subdivision of the icosahedron
+	maxFrequency = 32
+	// ringTolerance is how far, as a fraction of the diameter, the ring of
+	// hubs a dome is cut at may be from its truncation
+	ringTolerance = 0.01
+)
+
+// Geodesic is a dome of struts between hubs on a sphere, made by subdividing
+// the faces of an icosahedron and truncating the sphere at its base
 type Geodesic struct {
 	m        *model.Skyciv
 	Diameter float64
-	Chord    float64
+	// Chord is the longest strut allowed.  Each face of the icosahedron is
+	// divided into smaller triangles until the struts are no longer.
+	Chord float64
+	// Truncation is the fraction of the height of the sphere that is kept,
+	// defaults to 0.5 for a hemisphere cut at the equator.  The dome is cut
+	// at the ring of hubs nearest the truncation, which must be within 1% of
+	// the diameter of it.  Odd frequencies have no ring at the equator.
+	Truncation float64
+	// Strut is the nominal size of the sawn lumber struts, defaults to
+	// "2 x 6"
+	Strut string
+	// BaseRestraint is the restraint code of the hubs around the base,
+	// defaults to model.RestraintFixed
+	BaseRestraint string
+	Design
+
+	frequency int
+	hubs      []*model.Node
+	struts    []*model.ContinuousMember
+	triangles [][3]*model.Node
+}
+
+// NewGeodesic is a hemisphere of 30 ft with struts of up to 6 ft
+func NewGeodesic() *Geodesic {
+	return &Geodesic{
+		Diameter:   30,
+		Chord:      6,
+		Truncation: 0.5,
+		Strut:      "2 x 6",
+		Design: Design{
+			RoofLiveLoad: 0.02,
+			RoofDeadLoad: 0.01,
+		},
+	}
+}
 
-	mats *model.MaterialFile
+func (g *Geodesic) Parameters() []Parameter {
+	return append(append([]Parameter{
+		{"diameter", "diameter of the sphere in ft", &g.Diameter},
+		{"chord", "longest strut in ft", &g.Chord},
+		{"truncation", "fraction of the height of the sphere that is kept", &g.Truncation},
+		{"strut", "nominal size of the struts", &g.Strut},
+		{"base", "restraint code of the hubs around the base, empty for fixed", &g.BaseRestraint},
+	}, g.roofParameters()...), g.seismicParameters()...)
 }
 
-type polar struct {
-	theta float64
-	fie   float64
+func (g *Geodesic) Model() *model.Skyciv {
+	return g.m
+}
+
+// Frequency is the number of struts each edge of the icosahedron was divided
+// into
+func (g *Geodesic) Frequency() int {
+	return g.frequency
+}
+
+// sphere is a triangulation of the unit sphere
+type sphere struct {
+	points    []model.Vector
+	triangles [][3]int
+}
+
+// icosahedron has a vertex at the top and bottom with the others in two rings
+// of five between them
+func icosahedron() sphere {
+	s := sphere{points: []model.Vector{{Y: 1}}}
+	y, r := 1/math.Sqrt(5), 2/math.Sqrt(5)
+	for i := 0; i < 5; i++ {
+		t := float64(i) * 2 * math.Pi / 5
+		s.points = append(s.points, model.Vector{X: r * math.Cos(t), Y: y, Z: r * math.Sin(t)})
+	}
+	for i := 0; i < 5; i++ {
+		t := (float64(i) + 0.5) * 2 * math.Pi / 5
+		s.points = append(s.points, model.Vector{X: r * math.Cos(t), Y: -y, Z: r * math.Sin(t)})
+	}
+	s.points = append(s.points, model.Vector{Y: -1})
+
+	for i := 0; i < 5; i++ {
+		j := (i + 1) % 5
+		upper, nextUpper := 1+i, 1+j
+		lower, nextLower := 6+i, 6+j
+		s.triangles = append(s.triangles,
+			[3]int{0, nextUpper, upper},
+			[3]int{upper, nextUpper, lower},
+			[3]int{nextUpper, nextLower, lower},
+			[3]int{11, lower, nextLower})
+	}
+	return s
+}
+
+// subdivide splits each face of the icosahedron into n^2 triangles and
+// projects their corners onto the unit sphere
+func subdivide(n int) sphere {
+	ico := icosahedron()
+	var s sphere
+	index := make(map[[3]int64]int)
+	point := func(v model.Vector) int {
+		v = v.Scale(1 / v.Length())
+		key := [3]int64{int64(math.Round(v.X * 1e9)), int64(math.Round(v.Y * 1e9)), int64(math.Round(v.Z * 1e9))}
+		if i, ok := index[key]; ok {
+			return i
+		}
+		index[key] = len(s.points)
+		s.points = append(s.points, v)
+		return len(s.points) - 1
+	}
+
+	for _, t := range ico.triangles {
+		a, b, c := ico.points[t[0]], ico.points[t[1]], ico.points[t[2]]
+		// the point i steps from a toward b and j steps toward c
+		at := func(i, j int) int {
+			u, v := float64(i)/float64(n), float64(j)/float64(n)
+			return point(a.Scale(1 - u - v).Sum(b.Scale(u)).Sum(c.Scale(v)))
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; i+j < n; j++ {
+				s.triangles = append(s.triangles, [3]int{at(i, j), at(i+1, j), at(i, j+1)})
+				if i+j < n-1 {
+					s.triangles = append(s.triangles, [3]int{at(i+1, j), at(i+1, j+1), at(i, j+1)})
+				}
+			}
+		}
+	}
+	return s
+}
+
+// longest is the longest edge of the triangulation
+func (s sphere) longest() (l float64) {
+	for _, t := range s.triangles {
+		for k := 0; k < 3; k++ {
+			l = math.Max(l, s.points[t[k]].Diff(s.points[t[(k+1)%3]]).Length())
+		}
+	}
+	return
+}
+
+// triangulate finds the lowest frequency whose struts are no longer than
+// Chord
+func (g *Geodesic) triangulate() (sphere, error) {
+	r := g.Diameter / 2
+	for n := 1; n <= maxFrequency; n++ {
+		s := subdivide(n)
+		if s.longest()*r <= g.Chord {
+			g.frequency = n
+			return s, nil
+		}
+	}
+	return sphere{}, fmt.Errorf("struts of %f ft need more than %d subdivisions", g.Chord, maxFrequency)
 }
 
-/* finds the number of triangularuzation steps of an icosohedron before the chord length is below Chord */
-func (g *Geodesic) findTriangularization() {
-	phi := (1 + math.Sqrt(5))/2
-	
-	// first start wih an icosohedron
-	nodes = []polar{}{
-		polar{}
+func (g *Geodesic) roofAreaLoad(mag float64, loadGroup string) error {
+	for _, t := range g.triangles {
+		al, err := g.m.NewAreaLoad(t[:]...)
+		if err != nil {
+			return err
+		}
+		al.LoadGroup = loadGroup
+		al.Direction = "Y"
+		al.Mag = mag
 	}
+	return nil
+}
+
+func (g *Geodesic) Build(materialName string) error {
+	if g.Diameter <= 0 || g.Chord <= 0 {
+		return fmt.Errorf("domes need a diameter and chord")
+	}
+	if g.Snow.Ground > 0 || g.Wind.Speed > 0 {
+		return fmt.Errorf("snow and wind loads are not supported on domes")
+	}
+	truncation := g.Truncation
+	if truncation == 0 {
+		truncation = 0.5
+	}
+	if truncation < 0 || truncation > 1 {
+		return fmt.Errorf("truncation %f is not a fraction of the sphere", truncation)
+	}
+	strut := g.Strut
+	if strut == "" {
+		strut = "2 x 6"
+	}
+
+	g.m = model.NewModel(g.MaterialFile)
+	g.hubs, g.struts, g.triangles = nil, nil, nil
+	secs, err := sawnLumber(g.m, materialName, strut)
+	if err != nil {
+		return err
+	}
+
+	s, err := g.triangulate()
+	if err != nil {
+		return err
+	}
+
+	// cut the sphere at the ring of hubs nearest the truncation, preferring
+	// the lower of two that are as near
+	r := g.Diameter / 2
+	cut := 1 - 2*truncation
+	base := math.Inf(1)
+	for _, p := range s.points {
+		if d := math.Abs(p.Y-cut) - math.Abs(base-cut); d < -1e-9 || d < 1e-9 && p.Y < base {
+			base = p.Y
+		}
+	}
+	if math.Abs(base-cut)*r > ringTolerance*g.Diameter {
+		return fmt.Errorf("truncation %f is %.2f ft from the nearest ring of hubs of a %dV dome, at truncation %.3f",
+			truncation, math.Abs(base-cut)*r, g.frequency, (1-base)/2)
+	}
+
+	var kept [][3]int
+	for _, t := range s.triangles {
+		if s.points[t[0]].Y >= base-1e-9 && s.points[t[1]].Y >= base-1e-9 && s.points[t[2]].Y >= base-1e-9 {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		return fmt.Errorf("truncation %f leaves no dome", truncation)
+	}
+
+	// the ring sits on the ground
+	hubs := make(map[int]*model.Node)
+	hub := func(i int) *model.Node {
+		if n, ok := hubs[i]; ok {
+			return n
+		}
+		p := s.points[i]
+		ring := math.Abs(p.Y-base) < 1e-9
+		if ring {
+			p.Y = base
+		}
+		n := g.m.NewNode(r*p.X, r*(p.Y-base), r*p.Z)
+		if ring {
+			supportBase(n, g.BaseRestraint)
+		}
+		hubs[i] = n
+		g.hubs = append(g.hubs, n)
+		return n
+	}
+
+	// hubs along the base that are above the ring are left unsupported
+	edges := make(map[[2]int]bool)
+	for _, t := range kept {
+		g.triangles = append(g.triangles, [3]*model.Node{hub(t[0]), hub(t[1]), hub(t[2])})
+		for k := 0; k < 3; k++ {
+			e := [2]int{t[k], t[(k+1)%3]}
+			if e[0] > e[1] {
+				e[0], e[1] = e[1], e[0]
+			}
+			if !edges[e] {
+				edges[e] = true
+				g.struts = append(g.struts, g.m.NewContinuousMemberBetweenNodes(secs[0], hubs[e[0]], hubs[e[1]]))
+			}
+		}
+	}
+
+	if err := g.roofAreaLoad(-g.RoofDeadLoad, "dead"); err != nil {
+		return err
+	}
+	if err := g.roofAreaLoad(-g.RoofLiveLoad, "roof live"); err != nil {
+		return err
+	}
+
+	sw := g.m.NewSelfWeight()
+	sw.LoadGroup = "SW1"
+	sw.Y = -1
+
+	var seismicGroups []string
+	if g.Seismic.SS > 0 {
+		if seismicGroups, err = seismicLoads(g.m, g.Seismic, g.Snow, nil, "dead", "SW1"); err != nil {
+			return err
+		}
+	}
+
+	g.m.LoadCombinations.Mapping.DeadCases("dead", "SW1").RoofLiveCases("roof live").SeismicCases(seismicGroups...)
+	return g.m.UseCombinations(g.Combinations)
 }
@@ -0,0 +1,86 @@
+package frames
+
+import (
+	"math"
+	"testing"
+
+	"github.com/donniet/goframes/model"
+)
+
+func TestGeodesic(T *testing.T) {
+	mats := materials(T)
+	for _, c := range []struct {
+		diameter, chord, truncation float64
+	}{
+		{30, 6, 0.5},
+		{30, 9, 0.586},
+		{30, 5, 0.625},
+		{20, 3, 0.444},
+	} {
+		g := NewGeodesic()
+		g.Diameter, g.Chord, g.Truncation = c.diameter, c.chord, c.truncation
+		g.MaterialFile = mats
+		if err := g.Build("Red Pine"); err != nil {
+			T.Errorf("%+v: %v", c, err)
+			continue
+		}
+		m := g.Model()
+		r := c.diameter / 2
+
+		longest := 0.
+		for _, s := range g.struts {
+			longest = math.Max(longest, s.Length())
+		}
+		if longest > c.chord {
+			T.Errorf("%+v: strut of %f ft is longer than the chord", c, longest)
+		}
+		if n := g.Frequency(); n > 1 && subdivide(n-1).longest()*r <= c.chord {
+			T.Errorf("%+v: frequency %d is not the lowest with struts under the chord", c, n)
+		}
+
+		// the top hub is a radius above the center of the sphere
+		top := 0.
+		for _, n := range g.hubs {
+			top = math.Max(top, n.Y)
+		}
+		center := model.Vector{Y: top - r}
+		for _, n := range g.hubs {
+			if d := n.ToVector().Diff(center).Length(); math.Abs(d-r) > 1e-9 {
+				T.Errorf("%+v: hub %d is %f ft from the center", c, n.Id, d)
+			}
+		}
+		if h, want := top/c.diameter, c.truncation; math.Abs(h-want) > ringTolerance {
+			T.Errorf("%+v: dome is %f of the sphere high", c, h)
+		}
+
+		if len(m.Supports) < 3 {
+			T.Errorf("%+v: dome stands on %d supports", c, len(m.Supports))
+		}
+		for _, s := range m.Supports {
+			if y := m.Nodes[s.Node].Y; y != 0 {
+				T.Errorf("%+v: support %d is %f ft off the ground", c, s.Id, y)
+			}
+		}
+		if ds := m.Validate(); len(ds) > 0 {
+			T.Errorf("%+v: %v", c, ds)
+		}
+	}
+
+	for _, c := range []struct {
+		diameter, chord, truncation float64
+	}{
+		{0, 6, 0.5},
+		{30, 0, 0.5},
+		{30, -1, 0.5},
+		{30, 6, 1.5},
+		// 3V domes have no ring of hubs at the equator
+		{30, 9, 0.5},
+	} {
+		g := NewGeodesic()
+		g.Diameter, g.Chord, g.Truncation = c.diameter, c.chord, c.truncation
+		g.MaterialFile = mats
+		if err := g.Build("Red Pine"); err == nil {
+			T.Errorf("%+v built", c)
+		}
+	}
+}
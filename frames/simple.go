@@ -2,151 +2,333 @@ package frames
 
 import (
 	"fmt"
-	"os"
+	"math"
+	"strings"
 
+	"github.com/donniet/goframes/asce7"
 	"github.com/donniet/goframes/model"
 )
 
 type SimpleFrame struct {
-	m            *model.Skyciv
-	Width        float64
-	Height       float64
-	Length       float64
-	TieHeight    float64
-	BraceRise    float64
-	RoofRise     float64
-	RoofRun      float64
-	Bents        int
-	MaterialFile *model.MaterialFile
-
-	RoofSnowLoad float64
-	RoofLiveLoad float64
-	RoofDeadLoad float64
-	// WindLiveLoad float64
-	WindSpeed  float64
-	AirDensity float64
-
-	posts []*model.ContinuousMember
+	m         *model.Skyciv
+	Width     float64
+	Height    float64
+	Length    float64
+	TieHeight float64
+	BraceRise float64
+	RoofRise  float64
+	RoofRun   float64
+	// Bents is the number of bents spaced evenly over Length
+	Bents int
+	// Bays are the spacings in ft between each bent and the next, which
+	// replace Bents and Length when given
+	Bays []float64
+	// Truss is the name of the truss that joins the posts and rafters of each
+	// bent, one of TrussNames, which defaults to TrussTieBeam
+	Truss string
+	// BaseRestraint is the restraint code of the post bases, defaults to
+	// model.RestraintFixed.  Posts on sills behave more like model.RestraintPinned.
+	BaseRestraint string
+	// SheathingThickness adds plates of this thickness (in) to the roof and
+	// walls so the diaphragm stiffness of the sheathing is modeled.  Sheathed
+	// frames must be solved by skyciv, the solver package has no plates.
+	SheathingThickness float64
+	// SheathingMaterial is the material of the sheathing plates, defaults to
+	// Plywood
+	SheathingMaterial string
+
+	Design
+
+	posts   []*model.ContinuousMember
+	rafters []*model.ContinuousMember
+	lines   []rafterLine
+}
+
+// rafterLine is the eaves and ridge of a pair of rafters, at a bent or in the
+// middle of a bay
+type rafterLine struct {
+	eaves [2]*model.Node
+	ridge *model.Node
+}
+
+// NewSimpleFrame is a frame 12 ft wide and 20 ft long with an 8/12 roof and
+// the default loads
+func NewSimpleFrame() *SimpleFrame {
+	return &SimpleFrame{
+		Width:     12,
+		Height:    10,
+		Length:    20,
+		TieHeight: 8.5,
+		BraceRise: 3,
+		RoofRise:  8,
+		RoofRun:   12,
+		Bents:     3,
+
+		SheathingMaterial: "Plywood",
+
+		Design: defaultDesign(),
+	}
+}
+
+func (f *SimpleFrame) Parameters() []Parameter {
+	return append(append(append([]Parameter{
+		{"width", "width of the bents in ft", &f.Width},
+		{"height", "height of the posts in ft", &f.Height},
+		{"length", "length of the frame in ft", &f.Length},
+		{"tie-height", "height of the tie beams of tie-beam trusses in ft", &f.TieHeight},
+		{"brace-rise", "rise of the knee braces in ft", &f.BraceRise},
+		{"rise", "rise of the roof per run", &f.RoofRise},
+		{"run", "run of the roof", &f.RoofRun},
+		{"bents", "number of bents", &f.Bents},
+		{"truss", "truss of each bent, one of " + strings.Join(TrussNames(), ", "), &f.Truss},
+		{"bays", "comma separated spacings of the bents in ft, replacing bents and length", &f.Bays},
+		{"base", "restraint code of the post bases, empty for fixed", &f.BaseRestraint},
+		{"sheathing", "thickness in in of sheathing plates on the roof and walls, 0 for none", &f.SheathingThickness},
+		{"sheathing-material", "material of the sheathing plates", &f.SheathingMaterial},
+	}, f.roofParameters()...), f.environmentParameters()...), f.seismicParameters()...)
 }
 
 func (f *SimpleFrame) Model() *model.Skyciv {
 	return f.m
 }
 
-func (f *SimpleFrame) Build(materialName string) {
+// truss finds the truss named by the frame
+func (f *SimpleFrame) truss() (Truss, error) {
+	name := f.Truss
+	if name == "" {
+		name = TrussTieBeam
+	}
+	t, ok := trusses[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown truss %q, expected one of %v", name, TrussNames())
+	}
+	return t, nil
+}
+
+// length is the distance from the first bent to the last
+func (f *SimpleFrame) length() float64 {
+	return f.lines[len(f.lines)-1].ridge.Z
+}
+
+func (f *SimpleFrame) Build(materialName string) error {
+	positions, err := bentPositions(f.Bents, f.Length, f.Bays)
+	if err != nil {
+		return err
+	}
+
 	f.m = model.NewModel(f.MaterialFile)
+	f.posts, f.rafters, f.lines = nil, nil, nil
 
-	post := f.m.NewSectionFromLibrary(f.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "8 x 10")
-	tie := f.m.NewSectionFromLibrary(f.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "8 x 10")
-	rafter := f.m.NewSectionFromLibrary(f.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "8 x 10")
-	brace := f.m.NewSectionFromLibrary(f.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "4 x 8")
-	plate := f.m.NewSectionFromLibrary(f.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "8 x 10")
+	secs, err := sawnLumber(f.m, materialName, "8 x 10", "8 x 10", "8 x 10", "4 x 8", "8 x 10")
+	if err != nil {
+		return err
+	}
+	post, tie, rafter, brace, plate := secs[0], secs[1], secs[2], secs[3], secs[4]
 
-	for z := 0.; z <= f.Length; z += f.Length / 2 {
-		f.bent(post, tie, rafter, brace, plate, z, f.Length/2)
+	truss, err := f.truss()
+	if err != nil {
+		return err
+	}
+	for _, z := range positions {
+		if err := f.bent(truss, post, tie, rafter, brace, plate, z); err != nil {
+			return err
+		}
 	}
 
-	f.roofAreaLoad(-f.RoofSnowLoad, "snow")
 	f.roofAreaLoad(-f.RoofDeadLoad, "dead")
-	f.roofAreaLoad(-f.RoofLiveLoad, "live")
+	f.roofAreaLoad(-f.RoofLiveLoad, "roof live")
 
-	f.windAreaLoad(f.WindSpeed, "wind")
+	var snowPatterns [][]string
+	if f.Snow.Ground > 0 {
+		if snowPatterns, err = f.snowLoads(); err != nil {
+			return err
+		}
+	}
+
+	// the snow drift splits the rafters, so the sheathing goes on after it
+	if f.SheathingThickness > 0 {
+		mat, ok := f.m.Materials[f.SheathingMaterial]
+		if !ok {
+			return fmt.Errorf("no sheathing material named %q", f.SheathingMaterial)
+		}
+		if err := f.sheathe(mat); err != nil {
+			return err
+		}
+	}
+
+	var windGroups []string
+	if f.Wind.Speed > 0 {
+		if windGroups, err = f.windLoads(); err != nil {
+			return err
+		}
+	}
 
 	sw := f.m.NewSelfWeight()
 	sw.LoadGroup = "SW1" // for some reason skyciv always uses SW1 for this...
 	sw.Y = -1
 
-	f.m.LoadCombinations.Mapping.DeadCases("dead", "SW1").LiveCases("live").SnowCases("snow")
-	f.m.LoadCombinations.Cases = []model.Case{
-		{Name: "ULS: 1. 1.4D", Dead: 1.4},
-		{Name: "ULS: 2. 1.2D + 1.6L + 0.5S", Dead: 1.2, Live: 1.6, Snow: 0.5},
-		{Name: "ULS: 3. 1.2D + 1.6S + L", Dead: 1.2, Snow: 1.6, Live: 1},
-		{Name: "ULS: 3. 1.2D + 1.6S + 0.5W", Dead: 1.2, Snow: 1.6, Wind: 0.5},
-		{Name: "ULS: 4. 1.2D + W + L + 0.5S", Dead: 1.2, Wind: 1, Live: 1, Snow: 0.5},
-		{Name: "ULS: 5. 1.2D + L + 0.2S", Dead: 1.2, Live: 1, Snow: 0.2},
-		{Name: "ULS: 6. 0.9D + W", Dead: 0.9, Wind: 1},
+	var seismicGroups []string
+	if f.Seismic.SS > 0 {
+		if seismicGroups, err = seismicLoads(f.m, f.Seismic, f.Snow, append(f.roofPanels(0), f.roofPanels(1)...), "dead", "SW1"); err != nil {
+			return err
+		}
 	}
 
+	f.m.LoadCombinations.Mapping.DeadCases("dead", "SW1").RoofLiveCases("roof live").SnowPatternCases(snowPatterns...).
+		WindCases(windGroups...).SeismicCases(seismicGroups...)
+	return f.m.UseCombinations(f.Combinations)
 }
 
-func (f *SimpleFrame) windAreaLoad(windSpeed float64, loadGroup string) {
-	presureMag := WindPressure(f.AirDensity, windSpeed)
-
-	// sides
-	for i := 0; i < 2; i++ {
-		z := f.Length / 2 * float64(i)
+// windLoads applies the ASCE 7 wind pressures to every wall and roof panel
+// for wind from each side of the frame.  Each case and direction is its own
+// load group, which are returned.
+func (f *SimpleFrame) windLoads() ([]string, error) {
+	// the panels of each surface, keyed by the side of the frame they face
+	walls := map[string][][]*model.Node{
+		"-X": f.wallPanels(0),
+		"+X": f.wallPanels(1),
+		"-Z": {f.gable(0)},
+		"+Z": {f.gable(len(f.posts)/2 - 1)},
+	}
+	roofs := map[string][][]*model.Node{
+		"-X": f.roofPanels(0),
+		"+X": f.roofPanels(1),
+	}
+	return windLoads(f.m, f.Wind, f.building(), walls, roofs, func(c asce7.WindCase, windward bool, distance float64, _ []*model.Node) float64 {
+		return c.RoofPressure(windward, distance)
+	})
+}
 
-		// roofTop := Height * Width / 2 * RoofRise / RoofRun
+// building describes the frame for the ASCE 7 loads
+func (f *SimpleFrame) building() asce7.Building {
+	return asce7.Building{
+		Width:      f.Width,
+		Length:     f.length(),
+		EaveHeight: f.Height,
+		RoofAngle:  math.Atan2(f.RoofRise, f.RoofRun) * 180 / math.Pi,
+	}
+}
 
-		// left side of building
-		nl := []*model.Node{
-			f.m.FindNearestNode(-f.Width/2, 0, z),
-			f.m.FindNearestNode(-f.Width/2, f.Height, z),
-			f.m.FindNearestNode(-f.Width/2, f.Height, z+f.Length/2),
-			f.m.FindNearestNode(-f.Width/2, 0, z+f.Length/2),
-		}
-		if al, err := f.m.NewAreaLoad(nl...); err != nil {
-			panic(err)
-		} else {
-			al.LoadGroup = loadGroup
-			al.Direction = "X"
-			al.Mag = presureMag
-		}
+// roofPanels lists the roof panels between rafters on side 0 (-X) or 1 (+X)
+// of the ridge, each from eave to ridge and back
+func (f *SimpleFrame) roofPanels(side int) (ret [][]*model.Node) {
+	for j := 1; j < len(f.lines); j++ {
+		a, b := f.lines[j-1], f.lines[j]
+		ret = append(ret, []*model.Node{a.eaves[side], a.ridge, b.ridge, b.eaves[side]})
 	}
+	return
+}
 
-	// roof
-	for i := 0; i < 4; i++ {
-		z := f.Length / 4 * float64(i)
+// wallPanels lists the wall panels between posts on side 0 (-X) or 1 (+X),
+// one for each bay
+func (f *SimpleFrame) wallPanels(side int) (ret [][]*model.Node) {
+	for j := 2 + side; j < len(f.posts); j += 2 {
+		a, b := f.posts[j-2], f.posts[j]
+		ret = append(ret, []*model.Node{a.Begin(), a.End(), b.End(), b.Begin()})
+	}
+	return
+}
 
-		roofTop := f.Height + f.Width/2*f.RoofRise/f.RoofRun
+// gable is the end wall of a bent, up to the ridge
+func (f *SimpleFrame) gable(bent int) []*model.Node {
+	p0, p1 := f.posts[2*bent], f.posts[2*bent+1]
+	// each bay adds a line in its middle and one at its bent
+	ridge := f.lines[2*bent].ridge
+	return []*model.Node{p0.Begin(), p1.Begin(), p1.End(), ridge, p0.End()}
+}
 
-		// left side of building
-		nl := []*model.Node{
-			f.m.FindNearestNode(-f.Width/2, f.Height, z),
-			f.m.FindNearestNode(0, roofTop, z),
-			f.m.FindNearestNode(0, roofTop, z+f.Length/4),
-			f.m.FindNearestNode(-f.Width/2, f.Height, z+f.Length/4),
-		}
-		if al, err := f.m.NewAreaLoad(nl...); err != nil {
-			panic(err)
-		} else {
-			al.LoadGroup = loadGroup
-			al.Direction = "X"
-			al.Mag = presureMag
-		}
+// snowLoads applies the ASCE 7 balanced snow to the roof and, for wind across
+// the ridge from either side, the unbalanced snow and the drift at the ridge.
+// It returns the snow patterns, each a list of load groups applied together.
+func (f *SimpleFrame) snowLoads() ([][]string, error) {
+	b := f.building()
+	r, err := f.Snow.Roof(b)
+	if err != nil {
+		return nil, err
+	}
+	sides := map[string][][]*model.Node{
+		"-X": f.roofPanels(0),
+		"+X": f.roofPanels(1),
 	}
-}
 
-func (f *SimpleFrame) roofAreaLoad(magnitude float64, loadGroup string) {
-	for i := 0; i < 4; i++ {
-		z := f.Length / 4 * float64(i)
+	if err := snowLoad(f.m, r.Balanced, b.RoofAngle, "snow balanced", append(sides["-X"], sides["+X"]...)...); err != nil {
+		return nil, err
+	}
+	patterns := [][]string{{"snow balanced"}}
+	if !r.Unbalanced {
+		return patterns, nil
+	}
 
-		roofTop := f.Height + f.Width/2*f.RoofRise/f.RoofRun
+	// the drift needs nodes on the rafters where it ends
+	drifts := map[*model.Node]*model.Node{}
+	if r.Drift > 0 {
+		for _, raf := range f.rafters {
+			n, err := splitFromTop(raf, r.DriftWidth)
+			if err != nil {
+				return nil, err
+			}
+			drifts[raf.Begin()] = n
+		}
+	}
 
-		// left side
-		nl := []*model.Node{
-			f.m.FindNearestNode(-f.Width/2, f.Height, z),
-			f.m.FindNearestNode(0, roofTop, z),
-			f.m.FindNearestNode(0, roofTop, z+f.Length/4),
-			f.m.FindNearestNode(-f.Width/2, f.Height, z+f.Length/4),
+	// wind blowing toward +X drifts snow onto the +X side
+	for _, d := range []struct{ name, windward, leeward string }{{"+X", "-X", "+X"}, {"-X", "+X", "-X"}} {
+		unbalanced := "snow unbalanced " + d.name
+		if err := snowLoad(f.m, r.Windward, b.RoofAngle, unbalanced, sides[d.windward]...); err != nil {
+			return nil, err
 		}
+		if err := snowLoad(f.m, r.Leeward, b.RoofAngle, unbalanced, sides[d.leeward]...); err != nil {
+			return nil, err
+		}
+		pattern := []string{unbalanced}
+
+		if r.Drift > 0 {
+			drift := "snow drift " + d.name
+			var panels [][]*model.Node
+			for _, nl := range sides[d.leeward] {
+				panels = append(panels, []*model.Node{drifts[nl[0]], nl[1], nl[2], drifts[nl[3]]})
+			}
+			if err := snowLoad(f.m, r.Drift, b.RoofAngle, drift, panels...); err != nil {
+				return nil, err
+			}
+			pattern = append(pattern, drift)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
 
-		if al, err := f.m.NewAreaLoad(nl...); err != nil {
-			panic(err)
-		} else {
-			al.LoadGroup = loadGroup
-			al.Direction = "Y"
-			al.Mag = magnitude
+// sheathe covers each roof and wall panel with a plate.  Roof plates pass
+// through every node of the rafters along their edges so they stay joined
+// to the rafters where the rafters are split.
+func (f *SimpleFrame) sheathe(mat *model.Material) error {
+	rafters := make(map[*model.Node]*model.ContinuousMember)
+	for _, r := range f.rafters {
+		rafters[r.Begin()] = r
+	}
+	var panels [][]*model.Node
+	for _, nl := range append(f.roofPanels(0), f.roofPanels(1)...) {
+		// up one rafter from its eave and down the other to its eave
+		up, down := rafters[nl[0]].Nodes(), rafters[nl[3]].Nodes()
+		sheet := append([]*model.Node{}, up...)
+		for i := len(down) - 1; i >= 0; i-- {
+			sheet = append(sheet, down[i])
 		}
+		panels = append(panels, sheet)
+	}
+	panels = append(panels, f.wallPanels(0)...)
+	panels = append(panels, f.wallPanels(1)...)
 
-		// right side
-		nl = []*model.Node{
-			f.m.FindNearestNode(f.Width/2, f.Height, z),
-			f.m.FindNearestNode(0, roofTop, z),
-			f.m.FindNearestNode(0, roofTop, z+f.Length/4),
-			f.m.FindNearestNode(f.Width/2, f.Height, z+f.Length/4),
+	for _, nl := range panels {
+		if _, err := f.m.NewPlate(mat, f.SheathingThickness, nl...); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
+func (f *SimpleFrame) roofAreaLoad(magnitude float64, loadGroup string) {
+	for _, nl := range append(f.roofPanels(0), f.roofPanels(1)...) {
 		if al, err := f.m.NewAreaLoad(nl...); err != nil {
 			panic(err)
 		} else {
@@ -157,52 +339,52 @@ func (f *SimpleFrame) roofAreaLoad(magnitude float64, loadGroup string) {
 	}
 }
 
-func (f *SimpleFrame) bent(post, tie, rafter, brace, plate *model.Section, z, betweenBents float64) {
+// bent adds the posts, rafters and truss of a bent at z and joins
+// it to the previous bent with plates and a pair of rafters in the middle of
+// the bay
+func (f *SimpleFrame) bent(truss Truss, post, tie, rafter, brace, plate *model.Section, z float64) error {
 	first := len(f.posts) == 0
 
 	post00 := f.m.NewContinuousMember(post, -f.Width/2, 0, z, -f.Width/2, f.Height, z)
 	post01 := f.m.NewContinuousMember(post, f.Width/2, 0, z, f.Width/2, f.Height, z)
 
-	post00.Begin().FixedSupport()
-	post01.Begin().FixedSupport()
+	supportBase(post00.Begin(), f.BaseRestraint)
+	supportBase(post01.Begin(), f.BaseRestraint)
 
 	rooftop := f.m.NewNode(0, f.Height+f.Width/2*f.RoofRise/f.RoofRun, z)
-	f.m.NewContinuousMemberBetweenNodes(rafter, post00.End(), rooftop)
-	f.m.NewContinuousMemberBetweenNodes(rafter, post01.End(), rooftop)
-
-	var tieBeam *model.ContinuousMember
-
-	if tieNode0, err := post00.Split(f.TieHeight); err != nil {
-		fmt.Fprintf(os.Stderr, "error splitting post for tie beam: %v\n", err)
-		return
-	} else if tieNode1, err := post01.Split(f.TieHeight); err != nil {
-		fmt.Fprintf(os.Stderr, "error splitting post for tie beam: %v\n", err)
-		return
-	} else {
-		tieBeam = f.m.NewContinuousMemberBetweenNodes(tie, tieNode0, tieNode1)
-	}
-
-	// add braces
-	if _, err := post00.Brace(tieBeam, brace, f.BraceRise, model.QuadrantNP); err != nil {
-		panic(err)
-	}
-	if _, err := post01.Brace(tieBeam, brace, f.BraceRise, model.QuadrantNN); err != nil {
-		panic(err)
+	rafter0 := f.m.NewContinuousMemberBetweenNodes(rafter, post00.End(), rooftop)
+	rafter1 := f.m.NewContinuousMemberBetweenNodes(rafter, post01.End(), rooftop)
+	f.rafters = append(f.rafters, rafter0, rafter1)
+	line := rafterLine{[2]*model.Node{post00.End(), post01.End()}, rooftop}
+
+	if err := truss(&Bent{
+		Frame:   f,
+		Model:   f.m,
+		Z:       z,
+		Posts:   [2]*model.ContinuousMember{post00, post01},
+		Rafters: [2]*model.ContinuousMember{rafter0, rafter1},
+		Timber:  tie,
+		Brace:   brace,
+	}); err != nil {
+		return err
 	}
 
 	// connect with top plates and plate braces
 	if !first {
+		prev0 := f.posts[len(f.posts)-2]
+		prev1 := f.posts[len(f.posts)-1]
+
 		// roof middle nodes
-		rtt := f.m.NewNode(0, f.Height+f.Width/2*f.RoofRise/f.RoofRun, z-f.Length/4)
-		rtt0 := f.m.NewNode(-f.Width/2, f.Height, z-f.Length/4)
-		rtt1 := f.m.NewNode(f.Width/2, f.Height, z-f.Length/4)
+		mid := (prev0.Begin().Z + z) / 2
+		rtt := f.m.NewNode(0, f.Height+f.Width/2*f.RoofRise/f.RoofRun, mid)
+		rtt0 := f.m.NewNode(-f.Width/2, f.Height, mid)
+		rtt1 := f.m.NewNode(f.Width/2, f.Height, mid)
 
 		// middle rafters
-		f.m.NewContinuousMemberBetweenNodes(rafter, rtt0, rtt)
-		f.m.NewContinuousMemberBetweenNodes(rafter, rtt1, rtt)
-
-		prev0 := f.posts[0]
-		prev1 := f.posts[1]
+		f.rafters = append(f.rafters,
+			f.m.NewContinuousMemberBetweenNodes(rafter, rtt0, rtt),
+			f.m.NewContinuousMemberBetweenNodes(rafter, rtt1, rtt))
+		f.lines = append(f.lines, rafterLine{[2]*model.Node{rtt0, rtt1}, rtt})
 
 		// connect with plates
 		plateA0 := f.m.NewContinuousMemberBetweenNodes(plate, post00.End(), rtt0)
@@ -211,18 +393,13 @@ func (f *SimpleFrame) bent(post, tie, rafter, brace, plate *model.Section, z, be
 		plateB1 := f.m.NewContinuousMemberBetweenNodes(plate, prev1.End(), rtt1)
 
 		// add plate braces
-		if _, err := post00.Brace(plateA0, brace, f.BraceRise, model.QuadrantNP); err != nil {
-			panic(err)
-		}
-		if _, err := post01.Brace(plateA1, brace, f.BraceRise, model.QuadrantNP); err != nil {
-			panic(err)
-		}
-		if _, err := f.posts[0].Brace(plateB0, brace, f.BraceRise, model.QuadrantNP); err != nil {
-			panic(err)
-		}
-		if _, err := f.posts[1].Brace(plateB1, brace, f.BraceRise, model.QuadrantNP); err != nil {
-			panic(err)
+		for _, pb := range [][2]*model.ContinuousMember{{post00, plateA0}, {post01, plateA1}, {prev0, plateB0}, {prev1, plateB1}} {
+			if err := addBrace(pb[0], pb[1], brace, f.BraceRise, model.QuadrantNP); err != nil {
+				return fmt.Errorf("bracing the plates of the bay ending at %f ft: %v", z, err)
+			}
 		}
 	}
-	f.posts = append([]*model.ContinuousMember{post00, post01}, f.posts...)
+	f.posts = append(f.posts, post00, post01)
+	f.lines = append(f.lines, line)
+	return nil
 }
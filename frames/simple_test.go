@@ -0,0 +1,97 @@
+package frames
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBentPositions(T *testing.T) {
+	for _, c := range []struct {
+		bents  int
+		length float64
+		bays   []float64
+		want   []float64
+	}{
+		{3, 20, nil, []float64{0, 10, 20}},
+		{5, 20, nil, []float64{0, 5, 10, 15, 20}},
+		{0, 0, []float64{8, 12, 6}, []float64{0, 8, 20, 26}},
+		// bays replace the bents and length
+		{3, 20, []float64{4}, []float64{0, 4}},
+	} {
+		z, err := bentPositions(c.bents, c.length, c.bays)
+		if err != nil {
+			T.Errorf("%+v: %v", c, err)
+		} else if !reflect.DeepEqual(z, c.want) {
+			T.Errorf("%+v: bents at %v", c, z)
+		}
+	}
+
+	for _, c := range []struct {
+		bents  int
+		length float64
+		bays   []float64
+	}{
+		{1, 20, nil},
+		{3, 0, nil},
+		{0, 0, []float64{8, 0}},
+		{0, 0, []float64{-2}},
+	} {
+		if z, err := bentPositions(c.bents, c.length, c.bays); err == nil {
+			T.Errorf("%+v: bents at %v", c, z)
+		}
+	}
+}
+
+func TestShortBays(T *testing.T) {
+	mats := materials(T)
+	for _, bays := range [][]float64{{2.5, 2.5, 2.5}, {1, 12}} {
+		f := NewSimpleFrame()
+		f.Bays = bays
+		f.MaterialFile = mats
+		if err := f.Build("Red Pine"); err != nil {
+			T.Errorf("bays %v: %v", bays, err)
+			continue
+		}
+		if ds := f.Model().Validate(); len(ds) > 0 {
+			T.Errorf("bays %v: %v", bays, ds)
+		}
+	}
+}
+
+func TestSheathing(T *testing.T) {
+	// a roof wide and low enough to drift
+	f := NewSimpleFrame()
+	f.Width, f.RoofRise = 48, 4
+	f.MaterialFile = materials(T)
+	f.SheathingThickness = 0.75
+	if err := f.Build("Red Pine"); err != nil {
+		T.Fatal(err)
+	}
+	if len(f.Model().Plates) == 0 {
+		T.Fatal("no sheathing plates")
+	}
+
+	// every rafter edges a roof plate, so each of its nodes, including those
+	// the snow drift splits it at, must be a node of a plate
+	plated := make(map[int]bool)
+	for _, p := range f.Model().Plates {
+		for _, id := range p.Nodes {
+			plated[id] = true
+		}
+	}
+	split := false
+	for _, r := range f.rafters {
+		split = split || len(r.Nodes()) > 2
+		for _, n := range r.Nodes() {
+			if !plated[n.Id] {
+				T.Errorf("rafter node %d at (%f, %f, %f) is not on a plate", n.Id, n.X, n.Y, n.Z)
+			}
+		}
+	}
+	if !split {
+		T.Errorf("no rafter is split by the snow drift")
+	}
+	if ds := f.Model().Validate(); len(ds) > 0 {
+		T.Error(ds)
+	}
+}
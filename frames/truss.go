@@ -0,0 +1,252 @@
+package frames
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/donniet/goframes/model"
+)
+
+// TrussTieBeam is the truss of a SimpleFrame when none is named
+const TrussTieBeam = "tie-beam"
+
+// Bent is a pair of posts and the rafters on them at the same distance along
+// a SimpleFrame, which a Truss joins across the width of the frame.  Side 0 is
+// at -X and side 1 at +X.  Posts begin at their bases and rafters at the
+// eaves.
+type Bent struct {
+	Frame   *SimpleFrame
+	Model   *model.Skyciv
+	Z       float64
+	Posts   [2]*model.ContinuousMember
+	Rafters [2]*model.ContinuousMember
+	// Timber is the section of the beams and posts of the truss and Brace the
+	// section of its struts and braces
+	Timber, Brace *model.Section
+}
+
+// Truss adds the members of a bent that join its posts and rafters
+type Truss func(b *Bent) error
+
+var trusses = map[string]Truss{
+	TrussTieBeam:  tieBeam,
+	"king-post":   kingPost,
+	"queen-post":  queenPost,
+	"hammer-beam": hammerBeam,
+	"scissor":     scissor,
+	"collar-tie":  collarTie,
+}
+
+// RegisterTruss adds a truss that SimpleFrame bents can be built with,
+// replacing any truss already registered under name
+func RegisterTruss(name string, t Truss) {
+	trusses[name] = t
+}
+
+// TrussNames lists the registered trusses in order
+func TrussNames() []string {
+	names := make([]string, 0, len(trusses))
+	for name := range trusses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Node is the node at x and y in the plane of the bent
+func (b *Bent) Node(x, y float64) *model.Node {
+	return b.Model.NewNode(x, y, b.Z)
+}
+
+// RoofHeight is the height of the top of the rafters at x
+func (b *Bent) RoofHeight(x float64) float64 {
+	f := b.Frame
+	return f.Height + (f.Width/2-math.Abs(x))*f.RoofRise/f.RoofRun
+}
+
+// RafterNode splits the rafter on the side of x where it passes over x
+func (b *Bent) RafterNode(x float64) (*model.Node, error) {
+	side := 0
+	if x > 0 {
+		side = 1
+	}
+	return b.Rafters[side].SplitAt(x, b.RoofHeight(x), b.Z)
+}
+
+// Member adds a member of the truss between two nodes
+func (b *Bent) Member(sec *model.Section, n0, n1 *model.Node) *model.ContinuousMember {
+	return b.Model.NewContinuousMemberBetweenNodes(sec, n0, n1)
+}
+
+// Strut adds a member between two nodes that is pinned at both ends
+func (b *Bent) Strut(n0, n1 *model.Node) *model.ContinuousMember {
+	return b.Member(b.Brace, n0, n1).Pin()
+}
+
+// BraceBetween braces mem against another member it shares a node with.  The
+// braces reach the BraceRise of the frame from the node, or half of the
+// shorter member if that is less.
+func (b *Bent) BraceBetween(mem, against *model.ContinuousMember, quadrant model.Quadrant) error {
+	if err := addBrace(mem, against, b.Brace, b.Frame.BraceRise, quadrant); err != nil {
+		return fmt.Errorf("bracing the bent at %f ft: %v", b.Z, err)
+	}
+	return nil
+}
+
+// eaveTie adds a tie beam between the tops of the posts and braces the posts
+// against it
+func (b *Bent) eaveTie() (*model.ContinuousMember, error) {
+	tie := b.Member(b.Timber, b.Posts[0].End(), b.Posts[1].End())
+	if err := b.BraceBetween(b.Posts[0], tie, model.QuadrantNP); err != nil {
+		return nil, err
+	}
+	return tie, b.BraceBetween(b.Posts[1], tie, model.QuadrantNN)
+}
+
+// tieBeam joins the posts with a tie beam at the TieHeight of the frame that
+// is braced to the posts
+func tieBeam(b *Bent) error {
+	var tie *model.ContinuousMember
+	if tieNode0, err := b.Posts[0].Split(b.Frame.TieHeight); err != nil {
+		return fmt.Errorf("error splitting post for tie beam: %v", err)
+	} else if tieNode1, err := b.Posts[1].Split(b.Frame.TieHeight); err != nil {
+		return fmt.Errorf("error splitting post for tie beam: %v", err)
+	} else {
+		tie = b.Member(b.Timber, tieNode0, tieNode1)
+	}
+
+	if err := b.BraceBetween(b.Posts[0], tie, model.QuadrantNP); err != nil {
+		return err
+	}
+	return b.BraceBetween(b.Posts[1], tie, model.QuadrantNN)
+}
+
+// kingPost ties the eaves and hangs the middle of the tie from the ridge by a
+// king post, with struts from its foot to the middle of each rafter
+func kingPost(b *Bent) error {
+	tie, err := b.eaveTie()
+	if err != nil {
+		return err
+	}
+	foot, err := tie.SplitAt(0, b.Frame.Height, b.Z)
+	if err != nil {
+		return err
+	}
+	b.Member(b.Timber, foot, b.Rafters[0].End())
+
+	for _, x := range []float64{-b.Frame.Width / 4, b.Frame.Width / 4} {
+		n, err := b.RafterNode(x)
+		if err != nil {
+			return err
+		}
+		b.Strut(foot, n)
+	}
+	return nil
+}
+
+// queenPost ties the eaves and hangs the tie at its third points by a pair of
+// queen posts joined at the top by a straining beam, with struts from the
+// foot of each queen post to the middle of the rafter outside it
+func queenPost(b *Bent) error {
+	tie, err := b.eaveTie()
+	if err != nil {
+		return err
+	}
+	w := b.Frame.Width
+	var tops [2]*model.Node
+	for i, x := range []float64{-w / 6, w / 6} {
+		foot, err := tie.SplitAt(x, b.Frame.Height, b.Z)
+		if err != nil {
+			return err
+		}
+		if tops[i], err = b.RafterNode(x); err != nil {
+			return err
+		}
+		b.Member(b.Timber, foot, tops[i])
+
+		n, err := b.RafterNode(2 * x)
+		if err != nil {
+			return err
+		}
+		b.Strut(foot, n)
+	}
+	b.Member(b.Timber, tops[0], tops[1])
+	return nil
+}
+
+// hammerBeam carries each rafter on a hammer post standing on a hammer beam
+// cantilevered from the top of the post, which leaves the middle of the bent
+// open up to a collar beam between the tops of the hammer posts
+func hammerBeam(b *Bent) error {
+	w := b.Frame.Width
+	var hammerPosts [2]*model.ContinuousMember
+	for i, x := range []float64{-w / 3, w / 3} {
+		hammer := b.Member(b.Timber, b.Posts[i].End(), b.Node(x, b.Frame.Height))
+		top, err := b.RafterNode(x)
+		if err != nil {
+			return err
+		}
+		hammerPosts[i] = b.Member(b.Timber, hammer.End(), top)
+		if err := b.BraceBetween(b.Posts[i], hammer, model.QuadrantNP); err != nil {
+			return err
+		}
+	}
+
+	collar := b.Member(b.Timber, hammerPosts[0].End(), hammerPosts[1].End())
+	if err := b.BraceBetween(hammerPosts[0], collar, model.QuadrantNP); err != nil {
+		return err
+	}
+	return b.BraceBetween(hammerPosts[1], collar, model.QuadrantNN)
+}
+
+// scissor crosses a pair of ties from the top of each post to the middle of
+// the opposite rafter, joined where they cross, and braces the posts against
+// them
+func scissor(b *Bent) error {
+	w := b.Frame.Width
+	var ties [2]*model.ContinuousMember
+	for i, x := range []float64{w / 4, -w / 4} {
+		n, err := b.RafterNode(x)
+		if err != nil {
+			return err
+		}
+		ties[i] = b.Member(b.Timber, b.Posts[i].End(), n)
+	}
+
+	// the ties cross over the ridge two thirds of the way along them
+	y := b.Frame.Height + w/6*b.Frame.RoofRise/b.Frame.RoofRun
+	for _, t := range ties {
+		if _, err := t.SplitAt(0, y, b.Z); err != nil {
+			return err
+		}
+	}
+
+	for i, t := range ties {
+		if err := b.BraceBetween(b.Posts[i], t, model.QuadrantNP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collarTie joins the rafters halfway up the roof with a collar tie and braces
+// the posts against the rafters
+func collarTie(b *Bent) error {
+	w := b.Frame.Width
+	var ends [2]*model.Node
+	for i, x := range []float64{-w / 4, w / 4} {
+		var err error
+		if ends[i], err = b.RafterNode(x); err != nil {
+			return err
+		}
+	}
+	b.Member(b.Timber, ends[0], ends[1])
+
+	for i := range b.Posts {
+		if err := b.BraceBetween(b.Posts[i], b.Rafters[i], model.QuadrantNP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
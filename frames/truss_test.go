@@ -0,0 +1,90 @@
+package frames
+
+import (
+	"math"
+	"testing"
+
+	"github.com/donniet/goframes/model"
+)
+
+// testBent is a bent of the default SimpleFrame with its posts and rafters
+// but no truss
+func testBent(T *testing.T) *Bent {
+	f := NewSimpleFrame()
+	m := model.NewModel(nil)
+	m.NewMaterial("test")
+	secs, err := sawnLumber(m, "test", "8 x 10", "4 x 8")
+	if err != nil {
+		T.Fatal(err)
+	}
+
+	b := &Bent{Frame: f, Model: m, Timber: secs[0], Brace: secs[1]}
+	ridge := m.NewNode(0, b.RoofHeight(0), 0)
+	for i, x := range []float64{-f.Width / 2, f.Width / 2} {
+		b.Posts[i] = m.NewContinuousMember(secs[0], x, 0, 0, x, f.Height, 0)
+		b.Rafters[i] = m.NewContinuousMemberBetweenNodes(secs[0], b.Posts[i].End(), ridge)
+	}
+	return b
+}
+
+func TestTrusses(T *testing.T) {
+	// members of each truss, with its braces
+	members := map[string]int{
+		TrussTieBeam:  3,
+		"king-post":   6,
+		"queen-post":  8,
+		"hammer-beam": 9,
+		"scissor":     4,
+		"collar-tie":  3,
+	}
+	mats := materials(T)
+	for _, name := range TrussNames() {
+		b := testBent(T)
+		if err := trusses[name](b); err != nil {
+			T.Errorf("%s: %v", name, err)
+			continue
+		}
+		if n := len(b.Model.ContinuousMembers.Members()) - 4; n != members[name] {
+			T.Errorf("%s has %d members instead of %d", name, n, members[name])
+		}
+
+		w := b.Frame.Width
+		for _, n := range b.Model.Nodes {
+			if math.Abs(n.X) > w/2+1e-9 || n.Y < -1e-9 || n.Y > b.RoofHeight(n.X)+1e-9 {
+				T.Errorf("%s node %d at %f, %f is outside the bent", name, n.Id, n.X, n.Y)
+			}
+		}
+
+		f := NewSimpleFrame()
+		f.Truss = name
+		f.MaterialFile = mats
+		if err := f.Build("Red Pine"); err != nil {
+			T.Errorf("%s: %v", name, err)
+		} else if ds := f.Model().Validate(); len(ds) > 0 {
+			T.Errorf("%s: %v", name, ds)
+		}
+	}
+
+	// the scissor ties are joined where they cross over the ridge
+	b := testBent(T)
+	if err := scissor(b); err != nil {
+		T.Fatal(err)
+	}
+	cross := b.Model.FindNearestNode(0, b.Frame.Height+b.Frame.Width/6*b.Frame.RoofRise/b.Frame.RoofRun, 0)
+	ties := 0
+	for _, mem := range b.Model.ContinuousMembers.Members() {
+		for _, n := range mem.Nodes() {
+			if n == cross {
+				ties++
+			}
+		}
+	}
+	if math.Abs(cross.X) > 1e-9 || ties != 2 {
+		T.Errorf("%d members cross at %f, %f", ties, cross.X, cross.Y)
+	}
+	f := NewSimpleFrame()
+	f.Truss = "scissors"
+	if err := f.Build("Red Pine"); err == nil {
+		T.Errorf("built an unknown truss")
+	}
+}
@@ -1,13 +1,311 @@
 package frames
 
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/donniet/goframes/asce7"
+	"github.com/donniet/goframes/model"
+)
+
 const (
 	Gravity = 32.174048554 // ft/sec^2
+
+	psfPerKsf = 1000.
 )
 
-func WindPressure(airDensity, windSpeed float64) float64 {
-	return 0.5 * airDensity / Gravity * windSpeed * windSpeed
+func centroid(nodes []*model.Node) (c model.Vector) {
+	for _, n := range nodes {
+		c = c.Sum(n.ToVector())
+	}
+	return c.Scale(1 / float64(len(nodes)))
+}
+
+// pressureLoad applies a pressure (ksf) acting toward the face of the panel
+// away from inside.  Area loads only act along the global axes so a panel
+// that is not square to them gets one area load for each component.
+func pressureLoad(m *model.Skyciv, inside model.Vector, mag float64, loadGroup string, nodes ...*model.Node) error {
+	if len(nodes) < 3 {
+		return fmt.Errorf("pressure panels must have at least 3 nodes")
+	}
+	a, b, c := nodes[0].ToVector(), nodes[1].ToVector(), nodes[2].ToVector()
+	normal := b.Diff(a).Cross(c.Diff(a))
+	normal.Normalize()
+	if normal.Dot(centroid(nodes).Diff(inside)) < 0 {
+		normal = normal.Scale(-1)
+	}
+
+	// positive pressure pushes against the outward normal
+	force := normal.Scale(-mag)
+	for _, comp := range []struct {
+		dir string
+		mag float64
+	}{{"X", force.X}, {"Y", force.Y}, {"Z", force.Z}} {
+		if math.Abs(comp.mag) < 1e-9 {
+			continue
+		}
+		al, err := m.NewAreaLoad(nodes...)
+		if err != nil {
+			return err
+		}
+		al.LoadGroup = loadGroup
+		al.Direction = comp.dir
+		al.Mag = comp.mag
+	}
+	return nil
+}
+
+// windLoads applies the ASCE 7 wind cases of a building that stands from
+// -Width/2 to Width/2 along X and 0 to Length along Z with its ridge along Z.
+// The wall and roof panels are keyed by the side of the building they face,
+// "-X", "+X", "-Z" or "+Z", and roofPressure is the pressure in psf of a case
+// on a roof panel on the windward side or not, distance ft from the windward
+// edge.  It returns the load group of each case and direction.
+func windLoads(m *model.Skyciv, w asce7.Wind, b asce7.Building, walls, roofs map[string][][]*model.Node,
+	roofPressure func(c asce7.WindCase, windward bool, distance float64, panel []*model.Node) float64) ([]string, error) {
+	cases, err := w.Cases(b)
+	if err != nil {
+		return nil, err
+	}
+	inside := model.Vector{X: 0, Y: b.EaveHeight / 2, Z: b.Length / 2}
+
+	// wind blowing toward +X first strikes the -X wall
+	type direction struct {
+		name              string
+		windward, leeward string
+		sides             []string
+		distance          func(model.Vector) float64
+	}
+	normal := []direction{
+		{"+X", "-X", "+X", []string{"-Z", "+Z"}, func(c model.Vector) float64 { return c.X + b.Width/2 }},
+		{"-X", "+X", "-X", []string{"-Z", "+Z"}, func(c model.Vector) float64 { return b.Width/2 - c.X }},
+	}
+	parallel := []direction{
+		{"+Z", "-Z", "+Z", []string{"-X", "+X"}, func(c model.Vector) float64 { return c.Z }},
+		{"-Z", "+Z", "-Z", []string{"-X", "+X"}, func(c model.Vector) float64 { return b.Length - c.Z }},
+	}
+
+	var groups []string
+	for _, c := range cases {
+		dirs := normal
+		if c.Direction == asce7.Parallel {
+			dirs = parallel
+		}
+		for _, d := range dirs {
+			group := "wind " + d.name + " " + strings.TrimPrefix(strings.TrimPrefix(c.Name, "normal "), "parallel ")
+			groups = append(groups, group)
+
+			wall := func(surface asce7.Surface, panels [][]*model.Node) error {
+				for _, nl := range panels {
+					p, err := c.WallPressure(surface, centroid(nl).Y)
+					if err != nil {
+						return err
+					}
+					if err := pressureLoad(m, inside, p/psfPerKsf, group, nl...); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if err := wall(asce7.WindwardWall, walls[d.windward]); err != nil {
+				return nil, err
+			}
+			if err := wall(asce7.LeewardWall, walls[d.leeward]); err != nil {
+				return nil, err
+			}
+			for _, s := range d.sides {
+				if err := wall(asce7.SideWall, walls[s]); err != nil {
+					return nil, err
+				}
+			}
+
+			for _, side := range []string{"-X", "+X"} {
+				windward := side == d.windward
+				for _, nl := range roofs[side] {
+					p := roofPressure(c, windward, d.distance(centroid(nl)), nl)
+					if err := pressureLoad(m, inside, p/psfPerKsf, group, nl...); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return groups, nil
+}
+
+// bentPositions finds the distance of each bent from the first, from the
+// spacings of the bays or else from a number of bents spaced evenly over a
+// length
+func bentPositions(bents int, length float64, bays []float64) ([]float64, error) {
+	if len(bays) == 0 {
+		if bents < 2 {
+			return nil, fmt.Errorf("frames need at least 2 bents, not %d", bents)
+		}
+		if length <= 0 {
+			return nil, fmt.Errorf("frames need a length")
+		}
+		bays = make([]float64, bents-1)
+		for i := range bays {
+			bays[i] = length / float64(len(bays))
+		}
+	}
+	z := []float64{0}
+	for _, b := range bays {
+		if b <= 0 {
+			return nil, fmt.Errorf("bays must be longer than 0 ft, not %f", b)
+		}
+		z = append(z, z[len(z)-1]+b)
+	}
+	return z, nil
+}
+
+// addBrace braces mem against another member it shares a node with.  The brace
+// reaches rise from the node, or half of the shorter member if that is less.
+func addBrace(mem, against *model.ContinuousMember, sec *model.Section, rise float64, quadrant model.Quadrant) error {
+	d := math.Min(rise, math.Min(mem.Length(), against.Length())/2)
+	_, err := mem.Brace(against, sec, d, quadrant)
+	return err
+}
+
+// snowLoad applies snow of p psf on the horizontal projection of each sloped
+// panel as a downward area load, which acts over the sloped area of the panel
+func snowLoad(m *model.Skyciv, p, angle float64, loadGroup string, panels ...[]*model.Node) error {
+	if p == 0 {
+		return nil
+	}
+	mag := -p / psfPerKsf * math.Cos(angle*math.Pi/180)
+	for _, nl := range panels {
+		al, err := m.NewAreaLoad(nl...)
+		if err != nil {
+			return err
+		}
+		al.LoadGroup = loadGroup
+		al.Direction = "Y"
+		al.Mag = mag
+	}
+	return nil
+}
+
+// splitFromTop adds a node to a sloping member at a horizontal distance from
+// its upper end
+func splitFromTop(mem *model.ContinuousMember, distance float64) (*model.Node, error) {
+	a, b := mem.Begin(), mem.End()
+	if a.Y > b.Y {
+		a, b = b, a
+	}
+	run := math.Hypot(b.X-a.X, b.Z-a.Z)
+	if distance >= run {
+		return a, nil
+	}
+	return mem.SplitAt(b.X+(a.X-b.X)*distance/run, b.Y+(a.Y-b.Y)*distance/run, b.Z+(a.Z-b.Z)*distance/run)
+}
+
+// sawnLumber adds a sawn lumber section of the material for each nominal size
+func sawnLumber(m *model.Skyciv, materialName string, nominal ...string) ([]*model.Section, error) {
+	mat, ok := m.Materials[materialName]
+	if !ok {
+		return nil, fmt.Errorf("no material named %q", materialName)
+	}
+	secs := make([]*model.Section, len(nominal))
+	for i, n := range nominal {
+		sec, err := m.NewSawnLumberSection(mat, n)
+		if err != nil {
+			return nil, err
+		}
+		secs[i] = sec
+	}
+	return secs, nil
+}
+
+// seismicSnow is the snow in psf that counts toward the seismic weight, 20% of
+// the flat roof snow load where it exceeds 30 psf by ASCE 7 12.7.2
+func seismicSnow(s asce7.Snow) (float64, error) {
+	if s.Ground == 0 {
+		return 0, nil
+	}
+	pf, err := s.FlatRoof()
+	if err != nil || pf <= 30 {
+		return 0, err
+	}
+	return 0.2 * pf, nil
+}
+
+// seismicLoads applies the ASCE 7 equivalent lateral forces of the seismic
+// weight of the dead load groups and the seismicSnow on the horizontal
+// projection of the roof panels to the nodes, in each direction along X and
+// Z.  Heights are measured from the lowest support.  It returns the load
+// group of each direction.  The vertical seismic load effect Ev = 0.2 SDS D
+// is left out, which matters for uplift and lightly loaded members.
+func seismicLoads(m *model.Skyciv, s asce7.Seismic, snow asce7.Snow, roof [][]*model.Node, deadGroups ...string) ([]string, error) {
+	weights, err := m.SeismicWeights(nil, deadGroups...)
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := seismicSnow(snow)
+	if err != nil {
+		return nil, err
+	}
+	if ps > 0 {
+		for _, nl := range roof {
+			plan := make([]model.Vector, len(nl))
+			for i, n := range nl {
+				plan[i] = model.Vector{X: n.X, Z: n.Z}
+			}
+			for i, a := range model.Tributary(plan) {
+				weights[nl[i]] += ps / psfPerKsf * a
+			}
+		}
+	}
+
+	base := math.Inf(1)
+	for _, sup := range m.Supports {
+		base = math.Min(base, m.Nodes[sup.Node].Y)
+	}
+	if math.IsInf(base, 1) {
+		return nil, fmt.Errorf("seismic loads need a supported base")
+	}
+
+	nodes := make([]*model.Node, 0, len(weights))
+	for n := range weights {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	ws, hs := make([]float64, len(nodes)), make([]float64, len(nodes))
+	for i, n := range nodes {
+		ws[i], hs[i] = weights[n], n.Y-base
+	}
+	forces, err := s.Forces(ws, hs)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	for _, d := range []struct {
+		name, dir string
+		sign      float64
+	}{{"+X", "X", 1}, {"-X", "X", -1}, {"+Z", "Z", 1}, {"-Z", "Z", -1}} {
+		group := "seismic " + d.name
+		groups = append(groups, group)
+		for i, n := range nodes {
+			if forces[i] == 0 {
+				continue
+			}
+			if _, err := m.NewPointLoad(n, d.dir, d.sign*forces[i], group); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return groups, nil
 }
 
-type Frame interface {
-	Build()
+// supportBase supports the base of a post with the restraint code, or fixes it
+// if the code is empty
+func supportBase(n *model.Node, restraint string) *model.Support {
+	if restraint == "" {
+		restraint = model.RestraintFixed
+	}
+	return n.NewSupport(restraint)
 }
@@ -0,0 +1,75 @@
+package frames
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/donniet/goframes/asce7"
+	"github.com/donniet/goframes/model"
+)
+
+func TestSeismicSnow(T *testing.T) {
+	light := asce7.Snow{Ground: 30, Exposure: asce7.ExposureC, RoofExposure: asce7.PartiallyExposed}
+	if ps, err := seismicSnow(light); err != nil || ps != 0 {
+		T.Errorf("flat roof snow under 30 psf adds %f psf, %v", ps, err)
+	}
+
+	heavy := asce7.Snow{Ground: 60, Exposure: asce7.ExposureC, RoofExposure: asce7.PartiallyExposed}
+	pf, err := heavy.FlatRoof()
+	if err != nil {
+		T.Fatal(err)
+	}
+	ps, err := seismicSnow(heavy)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if pf <= 30 || math.Abs(ps-0.2*pf) > 1e-9 {
+		T.Errorf("flat roof snow of %f psf adds %f psf", pf, ps)
+	}
+
+	// a 10 ft square roof sloping 10 ft to 12 ft carries 2 kip of dead load
+	// and snow on its 100 ft^2 plan
+	total := func(snow asce7.Snow) float64 {
+		m := model.NewModel(nil)
+		roof := []*model.Node{m.NewNode(0, 10, 0), m.NewNode(10, 12, 0), m.NewNode(10, 12, 10), m.NewNode(0, 10, 10)}
+		for _, n := range roof {
+			m.NewNode(n.X, 0, n.Z).FixedSupport()
+		}
+		al, err := m.NewAreaLoad(roof...)
+		if err != nil {
+			T.Fatal(err)
+		}
+		al.LoadGroup, al.Direction, al.Mag = "dead", "Y", -2/math.Hypot(10, 2)/10
+
+		s := asce7.Seismic{SS: 1, S1: 0.4, R: 4}
+		if _, err := seismicLoads(m, s, snow, [][]*model.Node{roof}, "dead"); err != nil {
+			T.Fatal(err)
+		}
+		sum := 0.
+		for _, pl := range m.PointLoads {
+			if pl.LoadGroup == "seismic +X" {
+				sum += pl.Mag.X
+			}
+		}
+		return sum
+	}
+	want := (2 + ps*100/psfPerKsf) / 2
+	if none, some := total(light), total(heavy); math.Abs(some/none-want) > 1e-9 {
+		T.Errorf("seismic snow scales the base shear by %f instead of %f", some/none, want)
+	}
+}
+
+// materials reads the materials.json of the repository
+func materials(T *testing.T) *model.MaterialFile {
+	r, err := os.Open("../materials.json")
+	if err != nil {
+		T.Fatal(err)
+	}
+	defer r.Close()
+	mats, err := model.ReadMaterials(r)
+	if err != nil {
+		T.Fatal(err)
+	}
+	return mats
+}
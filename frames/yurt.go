@@ -1,8 +1,10 @@
 package frames
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/donniet/goframes/asce7"
 	"github.com/donniet/goframes/model"
 )
 
@@ -15,57 +17,242 @@ type Yurt struct {
 	RoofRise       float64
 	RoofRun        float64
 	BraceRise      float64
-
-	RoofSnowLoad float64
-	RoofLiveLoad float64
-	RoofDeadLoad float64
-	// WindLiveLoad float64
-	WindSpeed    float64
-	AirDensity   float64
-	MaterialFile *model.MaterialFile
+	// BaseRestraint is the restraint code of the post bases, defaults to
+	// model.RestraintFixed.  Posts on pier blocks behave more like
+	// model.RestraintPinned.
+	BaseRestraint string
+	Design
 
 	posts     []*model.ContinuousMember
+	rafters   []*model.ContinuousMember
 	splits    []*model.Node
 	tops      []*model.Node
 	topsplits []*model.Node
 }
 
+// NewYurt is a yurt of 24 ft with a 4/12 roof and the default loads
+func NewYurt() *Yurt {
+	return &Yurt{
+		Diameter:       24,
+		CrownDiameter:  3,
+		Height:         10,
+		RoofRise:       4,
+		RoofRun:        12,
+		MaxPostSpacing: 12,
+		BraceRise:      3,
+		Design:         defaultDesign(),
+	}
+}
+
+func (y *Yurt) Parameters() []Parameter {
+	return append(append(append([]Parameter{
+		{"diameter", "diameter of the wall in ft", &y.Diameter},
+		{"crown", "diameter of the crown ring in ft", &y.CrownDiameter},
+		{"height", "height of the wall in ft", &y.Height},
+		{"rise", "rise of the roof per run", &y.RoofRise},
+		{"run", "run of the roof", &y.RoofRun},
+		{"post-spacing", "largest spacing of the posts in ft", &y.MaxPostSpacing},
+		{"base", "restraint code of the post bases, empty for fixed", &y.BaseRestraint},
+	}, y.roofParameters()...), y.environmentParameters()...), y.seismicParameters()...)
+}
+
 func (y *Yurt) Model() *model.Skyciv {
 	return y.m
 }
 
-func (y *Yurt) roofAreaLoad(mag float64, loadGroup string) {
+// roofPanels lists the roof panels between rafters, each from the crown to
+// the eave
+func (y *Yurt) roofPanels() (ret [][]*model.Node) {
 	for i, j := 0, 1; i < len(y.posts); i, j = i+1, j+1 {
 		p0, p1 := y.posts[i], y.posts[j%len(y.posts)]
+		ret = append(ret,
+			[]*model.Node{y.tops[i], y.topsplits[i], y.splits[i], p0.End()},
+			[]*model.Node{y.topsplits[i], y.tops[j%len(y.posts)], p1.End(), y.splits[i]})
+	}
+	return
+}
 
-		if al, err := y.m.NewAreaLoad(y.tops[i], y.topsplits[i], y.splits[i], p0.End()); err != nil {
+func (y *Yurt) roofAreaLoad(mag float64, loadGroup string) {
+	for _, nl := range y.roofPanels() {
+		if al, err := y.m.NewAreaLoad(nl...); err != nil {
 			panic(err)
 		} else {
 			al.LoadGroup = loadGroup
 			al.Direction = "Y"
 			al.Mag = mag
 		}
+	}
+}
 
-		if al, err := y.m.NewAreaLoad(y.topsplits[i], y.tops[j%len(y.posts)], p1.End(), y.splits[i]); err != nil {
-			panic(err)
-		} else {
-			al.LoadGroup = loadGroup
-			al.Direction = "Y"
-			al.Mag = mag
+// windLoads applies the ASCE 7 wind pressures to the wall panels between
+// posts and to the roof panels for wind from each side.  Each case and
+// direction is its own load group, which are returned.
+func (y *Yurt) windLoads() ([]string, error) {
+	cases, err := y.Wind.RoundCases(asce7.Round{
+		Diameter:   y.Diameter,
+		EaveHeight: y.Height,
+		RoofAngle:  math.Atan2(y.RoofRise, y.RoofRun) * 180 / math.Pi,
+	})
+	if err != nil {
+		return nil, err
+	}
+	inside := model.Vector{X: 0, Y: y.Height / 2, Z: 0}
+
+	var walls [][]*model.Node
+	for i, j := 0, 1; i < len(y.posts); i, j = i+1, j+1 {
+		p0, p1 := y.posts[i], y.posts[j%len(y.posts)]
+		walls = append(walls, []*model.Node{p0.Begin(), p0.End(), p1.End(), p1.Begin()})
+	}
+	roofs := y.roofPanels()
+
+	// around is the angle in degrees between the direction a panel faces and
+	// the direction the wind comes from
+	around := func(nl []*model.Node, wind model.Vector) float64 {
+		c := centroid(nl)
+		cos := -(c.X*wind.X + c.Z*wind.Z) / math.Hypot(c.X, c.Z)
+		return math.Acos(math.Max(-1, math.Min(1, cos))) * 180 / math.Pi
+	}
+
+	var groups []string
+	for _, c := range cases {
+		for _, d := range []struct {
+			name string
+			wind model.Vector
+		}{{"+X", model.Vector{X: 1}}, {"-X", model.Vector{X: -1}}, {"+Z", model.Vector{Z: 1}}, {"-Z", model.Vector{Z: -1}}} {
+			group := "wind " + d.name + " " + c.Name
+			groups = append(groups, group)
+
+			for _, nl := range walls {
+				p := c.WallPressure(around(nl, d.wind))
+				if err := pressureLoad(y.m, inside, p/psfPerKsf, group, nl...); err != nil {
+					return nil, err
+				}
+			}
+			for _, nl := range roofs {
+				cen := centroid(nl)
+				distance := y.Diameter/2 + cen.X*d.wind.X + cen.Z*d.wind.Z
+				p := c.RoofPressure(distance)
+				if err := pressureLoad(y.m, inside, p/psfPerKsf, group, nl...); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return groups, nil
+}
+
+// snowLoads applies the ASCE 7 snow to the cone of the roof, treating it like
+// a gable with its ridge at the crown.  For wind from each side the panels
+// facing the wind get the windward unbalanced snow and the rest the leeward
+// snow and drift.  It returns the snow patterns, each a list of load groups
+// applied together.
+func (y *Yurt) snowLoads() ([][]string, error) {
+	b := asce7.Building{
+		Width:      y.Diameter - y.CrownDiameter,
+		Length:     y.Diameter,
+		EaveHeight: y.Height,
+		RoofAngle:  math.Atan2(y.RoofRise, y.RoofRun) * 180 / math.Pi,
+	}
+	r, err := y.Snow.Roof(b)
+	if err != nil {
+		return nil, err
+	}
+	panels := y.roofPanels()
+
+	if err := snowLoad(y.m, r.Balanced, b.RoofAngle, "snow balanced", panels...); err != nil {
+		return nil, err
+	}
+	patterns := [][]string{{"snow balanced"}}
+	if !r.Unbalanced {
+		return patterns, nil
+	}
+
+	// the drift needs nodes on the rafters where it ends
+	drifts := map[*model.Node]*model.Node{}
+	if r.Drift > 0 {
+		for _, raf := range y.rafters {
+			n, err := splitFromTop(raf, r.DriftWidth)
+			if err != nil {
+				return nil, err
+			}
+			drifts[raf.Begin()] = n
 		}
 	}
+
+	for _, d := range []struct {
+		name string
+		wind model.Vector
+	}{{"+X", model.Vector{X: 1}}, {"-X", model.Vector{X: -1}}, {"+Z", model.Vector{Z: 1}}, {"-Z", model.Vector{Z: -1}}} {
+		var windward, leeward [][]*model.Node
+		for _, nl := range panels {
+			if c := centroid(nl); c.X*d.wind.X+c.Z*d.wind.Z > 0 {
+				leeward = append(leeward, nl)
+			} else {
+				windward = append(windward, nl)
+			}
+		}
+
+		unbalanced := "snow unbalanced " + d.name
+		if err := snowLoad(y.m, r.Windward, b.RoofAngle, unbalanced, windward...); err != nil {
+			return nil, err
+		}
+		if err := snowLoad(y.m, r.Leeward, b.RoofAngle, unbalanced, leeward...); err != nil {
+			return nil, err
+		}
+		pattern := []string{unbalanced}
+
+		if r.Drift > 0 {
+			drift := "snow drift " + d.name
+			var crown [][]*model.Node
+			for _, nl := range leeward {
+				crown = append(crown, []*model.Node{nl[0], nl[1], drifts[nl[2]], drifts[nl[3]]})
+			}
+			if err := snowLoad(y.m, r.Drift, b.RoofAngle, drift, crown...); err != nil {
+				return nil, err
+			}
+			pattern = append(pattern, drift)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// postCount is the number of posts around the wall, no farther apart than
+// MaxPostSpacing
+func (y *Yurt) postCount() float64 {
+	return math.Ceil(y.Diameter * math.Pi / y.MaxPostSpacing)
+}
+
+func (y *Yurt) validate() error {
+	if y.Diameter <= 0 || y.Height <= 0 {
+		return fmt.Errorf("yurts need a diameter and height")
+	}
+	if y.RoofRun <= 0 {
+		return fmt.Errorf("the roof needs a run")
+	}
+	if y.MaxPostSpacing <= 0 {
+		return fmt.Errorf("posts need a spacing")
+	}
+	if n := y.postCount(); n < 3 {
+		return fmt.Errorf("posts %f ft apart around a %f ft wall are %.0f, not the 3 or more needed", y.MaxPostSpacing, y.Diameter*math.Pi, n)
+	}
+	return nil
 }
 
 func (y *Yurt) Build(materialName string) error {
+	if err := y.validate(); err != nil {
+		return err
+	}
 	y.m = model.NewModel(y.MaterialFile)
+	y.posts, y.rafters, y.splits, y.tops, y.topsplits = nil, nil, nil, nil, nil
 
-	post := y.m.NewSectionFromLibrary(y.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "8 x 10")
-	tie := y.m.NewSectionFromLibrary(y.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "4 x 8")
-	rafter := y.m.NewSectionFromLibrary(y.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "4 x 8")
-	// brace := y.m.NewSectionFromLibrary(y.m.Materials[materialName], "American", "NDS", "Sawn Lumber", "4 x 8")
+	secs, err := sawnLumber(y.m, materialName, "8 x 10", "4 x 8", "4 x 8")
+	if err != nil {
+		return err
+	}
+	post, tie, rafter := secs[0], secs[1], secs[2]
 
-	// determine number of posts
-	count := math.Ceil(y.Diameter * math.Pi / y.MaxPostSpacing)
+	count := y.postCount()
 	r, cr := 0.5*y.Diameter, 0.5*y.CrownDiameter
 
 	// top of roof node (maybe this should be a circle or something instead?)
@@ -79,7 +266,7 @@ func (y *Yurt) Build(materialName string) error {
 		p := y.m.NewContinuousMember(post, x, 0, z, x, y.Height, z)
 		p.RotationAngle = -theta * 180. / math.Pi
 		// supported at the base
-		p.Begin().FixedSupport()
+		supportBase(p.Begin(), y.BaseRestraint)
 
 		y.posts = append(y.posts, p)
 
@@ -87,7 +274,7 @@ func (y *Yurt) Build(materialName string) error {
 
 		top := y.m.NewNode(x, y.Height+y.RoofRise*0.5*y.Diameter/y.RoofRun, z)
 
-		y.m.NewContinuousMemberBetweenNodes(rafter, p.End(), top)
+		y.rafters = append(y.rafters, y.m.NewContinuousMemberBetweenNodes(rafter, p.End(), top))
 
 		y.tops = append(y.tops, top)
 
@@ -102,11 +289,11 @@ func (y *Yurt) Build(materialName string) error {
 		tt := y.m.NewContinuousMemberBetweenNodes(tie, t0, t1)
 
 		if s, err := t.SplitPercent(0.5); err != nil {
-			panic(err)
+			return err
 		} else if ts, err := tt.SplitPercent(0.5); err != nil {
-			panic(err)
+			return err
 		} else {
-			y.m.NewContinuousMemberBetweenNodes(rafter, s, ts)
+			y.rafters = append(y.rafters, y.m.NewContinuousMemberBetweenNodes(rafter, s, ts))
 			y.splits = append(y.splits, s)
 			y.topsplits = append(y.topsplits, ts)
 		}
@@ -121,22 +308,37 @@ func (y *Yurt) Build(materialName string) error {
 	}
 
 	y.roofAreaLoad(-y.RoofDeadLoad, "dead")
-	y.roofAreaLoad(-y.RoofLiveLoad, "live")
-	y.roofAreaLoad(-y.RoofSnowLoad, "snow")
+	y.roofAreaLoad(-y.RoofLiveLoad, "roof live")
+
+	var snowPatterns [][]string
+	if y.Snow.Ground > 0 {
+		var err error
+		if snowPatterns, err = y.snowLoads(); err != nil {
+			return err
+		}
+	}
+
+	var windGroups []string
+	if y.Wind.Speed > 0 {
+		var err error
+		if windGroups, err = y.windLoads(); err != nil {
+			return err
+		}
+	}
 
 	sw := y.m.NewSelfWeight()
 	sw.LoadGroup = "SW1"
 	sw.Y = -1
 
-	y.m.LoadCombinations.Mapping.DeadCases("dead", "SW1").LiveCases("live").SnowCases("snow")
-	y.m.LoadCombinations.Cases = []model.Case{
-		{Name: "ULS: 1. 1.4D", Dead: 1.4},
-		{Name: "ULS: 2. 1.2D + 1.6L + 0.5S", Dead: 1.2, Live: 1.6, Snow: 0.5},
-		{Name: "ULS: 3. 1.2D + 1.6S + L", Dead: 1.2, Snow: 1.6, Live: 1},
-		{Name: "ULS: 3. 1.2D + 1.6S + 0.5W", Dead: 1.2, Snow: 1.6, Wind: 0.5},
-		{Name: "ULS: 4. 1.2D + W + L + 0.5S", Dead: 1.2, Wind: 1, Live: 1, Snow: 0.5},
-		{Name: "ULS: 5. 1.2D + L + 0.2S", Dead: 1.2, Live: 1, Snow: 0.2},
-		{Name: "ULS: 6. 0.9D + W", Dead: 0.9, Wind: 1},
+	var seismicGroups []string
+	if y.Seismic.SS > 0 {
+		var err error
+		if seismicGroups, err = seismicLoads(y.m, y.Seismic, y.Snow, y.roofPanels(), "dead", "SW1"); err != nil {
+			return err
+		}
 	}
-	return nil
+
+	y.m.LoadCombinations.Mapping.DeadCases("dead", "SW1").RoofLiveCases("roof live").SnowPatternCases(snowPatterns...).
+		WindCases(windGroups...).SeismicCases(seismicGroups...)
+	return y.m.UseCombinations(y.Combinations)
 }
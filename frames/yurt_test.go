@@ -0,0 +1,42 @@
+package frames
+
+import (
+	"testing"
+)
+
+func TestYurtValidate(T *testing.T) {
+	for _, c := range []struct {
+		name   string
+		change func(y *Yurt)
+	}{
+		{"no diameter", func(y *Yurt) { y.Diameter = 0 }},
+		{"no height", func(y *Yurt) { y.Height = 0 }},
+		{"no roof run", func(y *Yurt) { y.RoofRun = 0 }},
+		{"no post spacing", func(y *Yurt) { y.MaxPostSpacing = 0 }},
+		// a single post ties to itself with no length between
+		{"spacing past the circumference", func(y *Yurt) { y.MaxPostSpacing = 100 }},
+		{"two posts", func(y *Yurt) { y.MaxPostSpacing = 40 }},
+	} {
+		y := NewYurt()
+		c.change(y)
+		if err := y.validate(); err == nil {
+			T.Errorf("%s is valid", c.name)
+		}
+		if err := y.Build("Red Pine"); err == nil {
+			T.Errorf("%s built", c.name)
+		}
+	}
+
+	y := NewYurt()
+	y.MaterialFile = materials(T)
+	y.MaxPostSpacing = 25
+	if err := y.Build("Red Pine"); err != nil {
+		T.Fatal(err)
+	}
+	if len(y.posts) != 4 {
+		T.Errorf("%d posts 25 ft apart around a 24 ft yurt", len(y.posts))
+	}
+	if ds := y.Model().Validate(); len(ds) > 0 {
+		T.Error(ds)
+	}
+}
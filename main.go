@@ -1,94 +1,199 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/donniet/goframes/client"
 	"github.com/donniet/goframes/frames"
 	"github.com/donniet/goframes/model"
+	"github.com/donniet/goframes/nds"
+	"github.com/donniet/goframes/units"
 )
 
 var (
-	Width     = 12.
-	Height    = 10.
-	Length    = 20.
-	TieHeight = 8.5
-	BraceRise = 3.
-	RoofRise  = 8.
-	RoofRun   = 12.
-	Bents     = 3
-
-	RoofLiveLoad = 0.02 // ksf == 20 psf
-	RoofSnowLoad = 0.06 // ksf == 60 psf
-	RoofDeadLoad = 0.02 // ksf == 20 psf
-	WindLiveLoad = 0.
-
-	AirDensity = 0.000075 // kip/ft^3 = 0.075 lb/ft^3 = 1.2 kg/m^3
-	WindSpeed  = 177.     // ft/s = 120 mph = 54 m/s
-
 	materialFile string
 	material     string
-)
 
-const (
-	Gravity = 32.174048554 // ft/s^2 = 9.80665 m/s^2
+	solve      bool
+	skycivUser string
+	skycivKey  string
+	designCode string
+
+	combinations string
+	unitSystem   string
+	grade        string
+	wetService   bool
+	list         bool
 )
 
 func init() {
 	flag.StringVar(&materialFile, "materials", "materials.json", "path to materials json file")
 	flag.StringVar(&material, "mat", "Red Pine", "material to build frame from")
+	flag.BoolVar(&solve, "solve", false, "send the model to skyciv and print the results instead of the model")
+	flag.StringVar(&skycivUser, "user", os.Getenv("SKYCIV_USERNAME"), "skyciv username")
+	flag.StringVar(&skycivKey, "key", os.Getenv("SKYCIV_KEY"), "skyciv api key")
+	flag.StringVar(&designCode, "design", "", "skyciv member design code to check against, e.g. NDS_2018")
+	flag.StringVar(&combinations, "combinations", model.CombinationsASCE7LRFD,
+		"load combinations to check, one of "+strings.Join(model.CombinationSetNames(), ", "))
+	flag.StringVar(&unitSystem, "units", "imperial", "units of the exported model, imperial or metric")
+	flag.StringVar(&grade, "grade", "", "lumber grade to check the solved members against with NDS, e.g. \"No. 2\"")
+	flag.BoolVar(&wetService, "wet", false, "check the members for wet service")
+	flag.BoolVar(&list, "list", false, "list the frames and their parameters")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [frame [parameters]]\n\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\nframes: %s (default yurt)\n", strings.Join(frames.Names(), ", "))
+	}
 	flag.Parse()
 }
 
+// listFrames prints each frame with its parameters and their defaults
+func listFrames() {
+	for _, name := range frames.Names() {
+		f, err := frames.New(name)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: %s\n", name, frames.Description(name))
+		fs := frames.FlagSet(name, f, flag.ContinueOnError)
+		fs.SetOutput(os.Stdout)
+		fs.PrintDefaults()
+		fmt.Println()
+	}
+}
+
 func main() {
-	// f := &frames.SimpleFrame{
-	// 	Width:        Width,
-	// 	Height:       Height,
-	// 	Length:       Length,
-	// 	TieHeight:    TieHeight,
-	// 	BraceRise:    BraceRise,
-	// 	RoofRise:     RoofRise,
-	// 	RoofRun:      RoofRun,
-	// 	Bents:        Bents,
-	// 	RoofSnowLoad: RoofSnowLoad,
-	// 	RoofLiveLoad: RoofLiveLoad,
-	// 	RoofDeadLoad: RoofDeadLoad,
-	// 	WindSpeed:    WindSpeed,
-	// 	AirDensity:   AirDensity,
-	// }
-	// f.Build()
+	if list {
+		listFrames()
+		return
+	}
+
+	name, args := "yurt", flag.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	f, err := frames.New(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	// exits on bad parameters
+	frames.FlagSet(name, f, flag.ExitOnError).Parse(args)
 
 	var mats *model.MaterialFile
-	if f, err := os.Open(materialFile); err != nil {
+	if r, err := os.Open(materialFile); err != nil {
 		panic(err)
-	} else if mats, err = model.ReadMaterials(f); err != nil {
+	} else if mats, err = model.ReadMaterials(r); err != nil {
 		panic(err)
 	}
 
-	f := &frames.Yurt{
-		Diameter:       24,
-		CrownDiameter:  3,
-		Height:         10,
-		RoofRise:       4,
-		RoofRun:        12,
-		MaxPostSpacing: 12,
-		BraceRise:      3,
+	f.Settings().MaterialFile = mats
+	f.Settings().Combinations = combinations
+	if err := f.Build(material); err != nil {
+		fmt.Fprintf(os.Stderr, "error building frame: %v\n", err)
+		os.Exit(1)
+	}
+
+	m := f.Model()
+	diagnostics := m.Validate()
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if err := diagnostics.Err(); err != nil {
+		os.Exit(1)
+	}
+	m.Renumber()
+
+	// frames are built in imperial units and exported in the units asked for
+	to, err := units.Preset(unitSystem)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := m.ConvertUnits(to); err != nil {
+		fmt.Fprintf(os.Stderr, "error converting units: %v\n", err)
+		os.Exit(1)
+	}
 
-		RoofSnowLoad: RoofSnowLoad,
-		RoofLiveLoad: RoofLiveLoad,
-		RoofDeadLoad: RoofDeadLoad,
-		WindSpeed:    WindSpeed,
-		AirDensity:   AirDensity,
-		MaterialFile: mats,
+	// members are checked on the solved model, so a check that cannot be run
+	// is reported before paying for the solve
+	var method nds.Method
+	if solve && grade != "" {
+		if to != units.Imperial {
+			fmt.Fprintf(os.Stderr, "NDS checks need imperial units, not %s\n", unitSystem)
+			os.Exit(2)
+		}
+		if method, err = ndsMethod(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
 	}
-	f.Build(material)
 
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "\t")
-	if err := enc.Encode(f.Model()); err != nil {
+
+	if solve {
+		c := client.New(skycivUser, skycivKey)
+		res, err := c.Solve(context.Background(), m, client.SolveOptions{DesignCode: designCode})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error solving model: %v\n", err)
+			os.Exit(1)
+		}
+		if err := enc.Encode(res); err != nil {
+			panic(err)
+		}
+		if grade != "" {
+			if err := check(m, mats, res, method); err != nil {
+				fmt.Fprintf(os.Stderr, "error checking members: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if err := enc.Encode(m); err != nil {
 		panic(err)
 	}
 	fmt.Println()
 }
+
+// ndsMethod is the NDS design method of the load combinations
+func ndsMethod() (nds.Method, error) {
+	switch combinations {
+	case model.CombinationsASCE7LRFD:
+		return nds.LRFD, nil
+	case model.CombinationsASCE7ASD, model.CombinationsIBCASD:
+		return nds.ASD, nil
+	}
+	return 0, fmt.Errorf("NDS checks need ASCE 7 or IBC load combinations, not %s", combinations)
+}
+
+// check rates the solved members against NDS and prints the governing
+// combination of each segment, most utilized first
+func check(m *model.Skyciv, mats *model.MaterialFile, res *client.Response, method nds.Method) error {
+	r, err := res.Results(m)
+	if err != nil {
+		return err
+	}
+	us, err := nds.CheckSkyciv(m, r, nds.Options{
+		Method:  method,
+		Service: nds.Service{Wet: wetService},
+		Values:  nds.FileValues(mats, m, grade),
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "member\tcase\tcombined\tcompression\tshear")
+	for _, u := range nds.Governing(us) {
+		fmt.Fprintf(w, "%d\t%s\t%.3f\t%.3f\t%.3f\n", u.Segment.Id, u.Case, u.Combined, u.Compression, u.Shear)
+	}
+	return w.Flush()
+}
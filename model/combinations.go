@@ -0,0 +1,145 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the load combination sets in CombinationSets
+const (
+	CombinationsASCE7LRFD = "asce7-lrfd"
+	CombinationsASCE7ASD  = "asce7-asd"
+	CombinationsIBCASD    = "ibc-asd-alt"
+	CombinationsEN1990    = "en1990"
+	CombinationsNBCC      = "nbcc"
+)
+
+// CombinationSet is a named list of load combinations from a design standard
+type CombinationSet struct {
+	Name        string
+	Description string
+	Cases       []Case
+}
+
+// CombinationSets are the load combinations of common design standards.  Rain
+// and flood loads are not modeled so their terms are left out.
+var CombinationSets = map[string]CombinationSet{
+	// ASCE 7-16 2.3.1 strength design with the seismic combinations of 2.3.6
+	CombinationsASCE7LRFD: {
+		Name:        CombinationsASCE7LRFD,
+		Description: "ASCE 7-16 strength design (LRFD)",
+		Cases: []Case{
+			{Name: "ULS: 1. 1.4D", Dead: 1.4},
+			{Name: "ULS: 2. 1.2D + 1.6L + 0.5Lr", Dead: 1.2, Live: 1.6, RoofLive: 0.5},
+			{Name: "ULS: 2. 1.2D + 1.6L + 0.5S", Dead: 1.2, Live: 1.6, Snow: 0.5},
+			{Name: "ULS: 3. 1.2D + 1.6Lr + L", Dead: 1.2, RoofLive: 1.6, Live: 1},
+			{Name: "ULS: 3. 1.2D + 1.6Lr + 0.5W", Dead: 1.2, RoofLive: 1.6, Wind: 0.5},
+			{Name: "ULS: 3. 1.2D + 1.6S + L", Dead: 1.2, Snow: 1.6, Live: 1},
+			{Name: "ULS: 3. 1.2D + 1.6S + 0.5W", Dead: 1.2, Snow: 1.6, Wind: 0.5},
+			{Name: "ULS: 4. 1.2D + W + L + 0.5Lr", Dead: 1.2, Wind: 1, Live: 1, RoofLive: 0.5},
+			{Name: "ULS: 4. 1.2D + W + L + 0.5S", Dead: 1.2, Wind: 1, Live: 1, Snow: 0.5},
+			{Name: "ULS: 5. 0.9D + W", Dead: 0.9, Wind: 1},
+			{Name: "ULS: 6. 1.2D + E + L + 0.2S", Dead: 1.2, Seismic: 1, Live: 1, Snow: 0.2},
+			{Name: "ULS: 7. 0.9D + E", Dead: 0.9, Seismic: 1},
+		},
+	},
+	// ASCE 7-16 2.4.1 allowable stress design with the seismic combinations of
+	// 2.4.5
+	CombinationsASCE7ASD: {
+		Name:        CombinationsASCE7ASD,
+		Description: "ASCE 7-16 allowable stress design (ASD)",
+		Cases: []Case{
+			{Name: "ASD: 1. D", Dead: 1},
+			{Name: "ASD: 2. D + L", Dead: 1, Live: 1},
+			{Name: "ASD: 3. D + Lr", Dead: 1, RoofLive: 1},
+			{Name: "ASD: 3. D + S", Dead: 1, Snow: 1},
+			{Name: "ASD: 4. D + 0.75L + 0.75Lr", Dead: 1, Live: 0.75, RoofLive: 0.75},
+			{Name: "ASD: 4. D + 0.75L + 0.75S", Dead: 1, Live: 0.75, Snow: 0.75},
+			{Name: "ASD: 5. D + 0.6W", Dead: 1, Wind: 0.6},
+			{Name: "ASD: 6. D + 0.75L + 0.45W + 0.75Lr", Dead: 1, Live: 0.75, Wind: 0.45, RoofLive: 0.75},
+			{Name: "ASD: 6. D + 0.75L + 0.45W + 0.75S", Dead: 1, Live: 0.75, Wind: 0.45, Snow: 0.75},
+			{Name: "ASD: 7. 0.6D + 0.6W", Dead: 0.6, Wind: 0.6},
+			{Name: "ASD: 8. D + 0.7E", Dead: 1, Seismic: 0.7},
+			{Name: "ASD: 9. D + 0.75L + 0.525E + 0.75S", Dead: 1, Live: 0.75, Seismic: 0.525, Snow: 0.75},
+			{Name: "ASD: 10. 0.6D + 0.7E", Dead: 0.6, Seismic: 0.7},
+		},
+	},
+	// IBC 2018 1605.3.2 alternative basic load combinations with omega = 1.3
+	// for wind loads calculated from ASCE 7
+	CombinationsIBCASD: {
+		Name:        CombinationsIBCASD,
+		Description: "IBC 2018 alternative basic allowable stress design",
+		Cases: []Case{
+			{Name: "ASD: 16-17. D + L + Lr", Dead: 1, Live: 1, RoofLive: 1},
+			{Name: "ASD: 16-17. D + L + S", Dead: 1, Live: 1, Snow: 1},
+			{Name: "ASD: 16-18. D + L + 0.78W", Dead: 1, Live: 1, Wind: 0.78},
+			{Name: "ASD: 16-19. D + L + 0.78W + 0.5S", Dead: 1, Live: 1, Wind: 0.78, Snow: 0.5},
+			{Name: "ASD: 16-20. D + L + S + 0.39W", Dead: 1, Live: 1, Snow: 1, Wind: 0.39},
+			{Name: "ASD: 16-21. D + L + S + E/1.4", Dead: 1, Live: 1, Snow: 1, Seismic: 1 / 1.4},
+			{Name: "ASD: 16-22. 0.9D + E/1.4", Dead: 0.9, Seismic: 1 / 1.4},
+		},
+	},
+	// EN 1990 6.10 and 6.12b with the recommended partial factors, and
+	// combination factors for category A floors, category H roofs and snow
+	// below 1000 m.  Roof live load is not combined with snow or wind per EN
+	// 1991-1-1 3.3.2.
+	CombinationsEN1990: {
+		Name:        CombinationsEN1990,
+		Description: "Eurocode EN 1990 persistent and seismic design situations",
+		Cases: []Case{
+			{Name: "ULS: 6.10 1.35G", Dead: 1.35},
+			{Name: "ULS: 6.10 1.35G + 1.5Qk,roof", Dead: 1.35, RoofLive: 1.5},
+			{Name: "ULS: 6.10 1.35G + 1.5Q + 0.75S + 0.9W", Dead: 1.35, Live: 1.5, Snow: 0.75, Wind: 0.9},
+			{Name: "ULS: 6.10 1.35G + 1.5S + 1.05Q + 0.9W", Dead: 1.35, Snow: 1.5, Live: 1.05, Wind: 0.9},
+			{Name: "ULS: 6.10 1.35G + 1.5W + 1.05Q + 0.75S", Dead: 1.35, Wind: 1.5, Live: 1.05, Snow: 0.75},
+			{Name: "ULS: 6.10 1.0G + 1.5W", Dead: 1, Wind: 1.5},
+			{Name: "ULS: 6.12b G + AEd + 0.3Q", Dead: 1, Seismic: 1, Live: 0.3},
+		},
+	},
+	// NBCC 2015 table 4.1.3.2.A, where roof live load is part of L
+	CombinationsNBCC: {
+		Name:        CombinationsNBCC,
+		Description: "National Building Code of Canada 2015 limit states design",
+		Cases: []Case{
+			{Name: "ULS: 1. 1.4D", Dead: 1.4},
+			{Name: "ULS: 2. 1.25D + 1.5L + 1.0S", Dead: 1.25, Live: 1.5, RoofLive: 1.5, Snow: 1},
+			{Name: "ULS: 2. 1.25D + 1.5L + 0.4W", Dead: 1.25, Live: 1.5, RoofLive: 1.5, Wind: 0.4},
+			{Name: "ULS: 3. 1.25D + 1.5S + 1.0L", Dead: 1.25, Snow: 1.5, Live: 1, RoofLive: 1},
+			{Name: "ULS: 3. 1.25D + 1.5S + 0.4W", Dead: 1.25, Snow: 1.5, Wind: 0.4},
+			{Name: "ULS: 4. 1.25D + 1.4W + 0.5L", Dead: 1.25, Wind: 1.4, Live: 0.5, RoofLive: 0.5},
+			{Name: "ULS: 4. 1.25D + 1.4W + 0.5S", Dead: 1.25, Wind: 1.4, Snow: 0.5},
+			{Name: "ULS: 4. 0.9D + 1.4W", Dead: 0.9, Wind: 1.4},
+			{Name: "ULS: 5. 1.0D + 1.0E + 0.5L + 0.25S", Dead: 1, Seismic: 1, Live: 0.5, RoofLive: 0.5, Snow: 0.25},
+		},
+	},
+}
+
+// CombinationSetNames lists the names of CombinationSets in order
+func CombinationSetNames() []string {
+	names := make([]string, 0, len(CombinationSets))
+	for name := range CombinationSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseCombinations replaces the cases of the model's load combinations with the
+// named set, defaulting to ASCE 7 LRFD if name is empty.  Cases with wind,
+// snow or seismic loads are split by wind group, snow pattern and seismic
+// group so map the load groups first.
+func (m *Skyciv) UseCombinations(name string) error {
+	if name == "" {
+		name = CombinationsASCE7LRFD
+	}
+	set, ok := CombinationSets[name]
+	if !ok {
+		return fmt.Errorf("unknown load combinations %q, expected one of %s", name, strings.Join(CombinationSetNames(), ", "))
+	}
+	m.LoadCombinations.Cases = append([]Case(nil), set.Cases...)
+	m.LoadCombinations.SplitWind()
+	m.LoadCombinations.SplitSnow()
+	m.LoadCombinations.SplitSeismic()
+	return nil
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUseCombinations(T *testing.T) {
+	m := NewModel(nil)
+	if err := m.UseCombinations("asce7-16"); err == nil {
+		T.Errorf("unknown combinations accepted")
+	}
+	if err := m.UseCombinations(""); err != nil {
+		T.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.LoadCombinations.Cases, CombinationSets[CombinationsASCE7LRFD].Cases) {
+		T.Errorf("empty name did not select ASCE 7 LRFD")
+	}
+
+	for _, name := range CombinationSetNames() {
+		set := CombinationSets[name]
+		if set.Name != name || len(set.Cases) == 0 {
+			T.Errorf("combination set %q is incomplete", name)
+		}
+		for _, c := range set.Cases {
+			if c.Dead == 0 {
+				T.Errorf("%s case %q has no dead load", name, c.Name)
+			}
+		}
+	}
+
+	// ASCE 7-16 numbers 0.9D + W before the seismic combinations of 2.3.6
+	lrfd := CombinationSets[CombinationsASCE7LRFD].Cases
+	for i, name := range []string{"ULS: 5. 0.9D + W", "ULS: 6. 1.2D + E + L + 0.2S", "ULS: 7. 0.9D + E"} {
+		if c := lrfd[len(lrfd)-3+i]; c.Name != name {
+			T.Errorf("LRFD case %d is %q, not %q", len(lrfd)-3+i, c.Name, name)
+		}
+	}
+	asd := CombinationSets[CombinationsASCE7ASD].Cases
+	if c := asd[len(asd)-1]; c.Name != "ASD: 10. 0.6D + 0.7E" || c.Dead != 0.6 || c.Seismic != 0.7 {
+		T.Errorf("last ASD case is %+v", c)
+	}
+}
+
+func TestCombinationCategories(T *testing.T) {
+	m := NewModel(nil)
+	m.LoadCombinations.Mapping.DeadCases("SW1", "dead").LiveCases("live").RoofLiveCases("roof live").
+		SnowCases("snow").WindCases("wind").SeismicCases("seismic")
+	if err := m.UseCombinations(CombinationsASCE7ASD); err != nil {
+		T.Fatal(err)
+	}
+
+	b, err := json.Marshal(&m.LoadCombinations)
+	if err != nil {
+		T.Fatal(err)
+	}
+	var c Combination
+	if err := json.Unmarshal(b, &c); err != nil {
+		T.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, m.LoadCombinations) {
+		T.Errorf("combinations changed after a round trip\n%+v\n%+v", c, m.LoadCombinations)
+	}
+
+	f := c.Mapping.Factors(c.Cases[11])
+	if f["dead"] != 1 || f["live"] != 0.75 || f["seismic"] != 0.525 || f["roof live"] != 0 {
+		T.Errorf("wrong factors for %q: %v", c.Cases[11].Name, f)
+	}
+}
+
+func TestCombinationOtherGroups(T *testing.T) {
+	in := map[int]map[string]interface{}{
+		1: {"name": "crane", "dead": 1.2, "LG1": 1.6, "Crane": 1.4, "live": 1.0, "live storage": 0.5},
+		2: {"name": "dead only", "dead": 1.4, "LG1": 0.0, "Crane": 0.0, "live": 0.0, "live storage": 0.0},
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		T.Fatal(err)
+	}
+	var c Combination
+	if err := json.Unmarshal(b, &c); err != nil {
+		T.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Mapping.Other, []string{"Crane", "LG1"}) {
+		T.Errorf("other groups %v", c.Mapping.Other)
+	}
+	for i, ca := range c.Cases {
+		f := c.Mapping.Factors(ca)
+		for g, v := range in[i+1] {
+			if v, ok := v.(float64); ok && f[g] != v {
+				T.Errorf("%s has factor %f for %s instead of %f", ca.Name, f[g], g, v)
+			}
+		}
+	}
+
+	// and the factors survive being written back out
+	out, err := json.Marshal(&c)
+	if err != nil {
+		T.Fatal(err)
+	}
+	var again Combination
+	if err := json.Unmarshal(out, &again); err != nil {
+		T.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, c) {
+		T.Errorf("combinations changed after a round trip\n%+v\n%+v", again, c)
+	}
+}
+
+func TestSplitWind(T *testing.T) {
+	m := NewModel(nil)
+	m.LoadCombinations.Mapping.DeadCases("dead").WindCases("wind +X", "wind -X")
+	if err := m.UseCombinations(CombinationsASCE7LRFD); err != nil {
+		T.Fatal(err)
+	}
+
+	windy := 0
+	for _, c := range CombinationSets[CombinationsASCE7LRFD].Cases {
+		if c.Wind != 0 {
+			windy++
+		}
+	}
+	if n := len(CombinationSets[CombinationsASCE7LRFD].Cases) + windy; len(m.LoadCombinations.Cases) != n {
+		T.Fatalf("%d cases instead of %d", len(m.LoadCombinations.Cases), n)
+	}
+	for _, c := range m.LoadCombinations.Cases {
+		f := m.LoadCombinations.Mapping.Factors(c)
+		if c.Wind != 0 && (f["wind +X"] == 0) == (f["wind -X"] == 0) {
+			T.Errorf("%q loads both or neither wind group: %v", c.Name, f)
+		}
+	}
+
+	b, err := json.Marshal(&m.LoadCombinations)
+	if err != nil {
+		T.Fatal(err)
+	}
+	var c Combination
+	if err := json.Unmarshal(b, &c); err != nil {
+		T.Fatal(err)
+	}
+	if b2, _ := json.Marshal(&c); string(b2) != string(b) {
+		T.Errorf("split cases changed after a round trip\n%s\n%s", b, b2)
+	}
+	if c.Cases[4].WindGroup != "wind +X" {
+		T.Errorf("wind group of %q is %q", c.Cases[4].Name, c.Cases[4].WindGroup)
+	}
+}
+
+func TestSplitSnow(T *testing.T) {
+	m := NewModel(nil)
+	m.LoadCombinations.Mapping.DeadCases("dead").
+		SnowPatternCases([]string{"snow balanced"}, []string{"snow unbalanced +X", "snow drift +X"})
+	if err := m.UseCombinations(CombinationsASCE7ASD); err != nil {
+		T.Fatal(err)
+	}
+
+	for _, c := range m.LoadCombinations.Cases {
+		if c.Snow == 0 {
+			continue
+		}
+		f := m.LoadCombinations.Mapping.Factors(c)
+		if (f["snow balanced"] == 0) == (f["snow unbalanced +X"] == 0) || f["snow unbalanced +X"] != f["snow drift +X"] {
+			T.Errorf("%q mixes snow patterns: %v", c.Name, f)
+		}
+	}
+
+	b, err := json.Marshal(&m.LoadCombinations)
+	if err != nil {
+		T.Fatal(err)
+	}
+	var c Combination
+	if err := json.Unmarshal(b, &c); err != nil {
+		T.Fatal(err)
+	}
+	if b2, _ := json.Marshal(&c); string(b2) != string(b) {
+		T.Errorf("snow patterns changed after a round trip\n%s\n%s", b, b2)
+	}
+}
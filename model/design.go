@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+)
+
+// DesignValues are the NDS reference design values of a grade of a wood
+// material, in material strength units
+type DesignValues struct {
+	// Material is the name of the material the grade is of
+	Material string `json:"material"`
+	Grade    string `json:"grade"`
+	// Glulam is true for glued laminated timber, which takes the volume factor
+	// instead of the size factor
+	Glulam bool    `json:"glulam,omitempty"`
+	Fb     float64 `json:"Fb"`
+	Ft     float64 `json:"Ft"`
+	Fv     float64 `json:"Fv"`
+	FcPerp float64 `json:"Fc_perp"`
+	Fc     float64 `json:"Fc"`
+	E      float64 `json:"E"`
+	Emin   float64 `json:"Emin"`
+	// Cfu is the flat use factor of beams and stringers of the grade loaded on
+	// their wide face, table 4D
+	Cfu float64 `json:"Cfu,omitempty"`
+
+	// BeamsAndStringers and PostsAndTimbers are the values of the grade in
+	// timbers 5 in and thicker, table 4D, when they differ from those of
+	// dimension lumber
+	BeamsAndStringers *DesignValues `json:"beams_and_stringers,omitempty"`
+	PostsAndTimbers   *DesignValues `json:"posts_and_timbers,omitempty"`
+}
+
+// scale multiplies the stresses of the grade and its timbers by strength
+func (g *DesignValues) scale(strength float64) {
+	g.Fb *= strength
+	g.Ft *= strength
+	g.Fv *= strength
+	g.FcPerp *= strength
+	g.Fc *= strength
+	g.E *= strength
+	g.Emin *= strength
+	for _, t := range []*DesignValues{g.BeamsAndStringers, g.PostsAndTimbers} {
+		if t != nil {
+			t.scale(strength)
+		}
+	}
+}
+
+// Grade finds the design values of a grade of a material in the file
+func (f *MaterialFile) Grade(material, grade string) (*DesignValues, error) {
+	for i, g := range f.Grades {
+		if g.Material == material && g.Grade == grade {
+			return &f.Grades[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no design values for %s %s", grade, material)
+}
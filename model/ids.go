@@ -0,0 +1,251 @@
+package model
+
+import (
+	"sort"
+)
+
+// kinds of object given ids by the model
+const (
+	idNode = iota
+	idSection
+	idMaterial
+	idSupport
+	idAreaLoad
+	idSelfWeight
+	idPointLoad
+	idMoment
+	idDistributedLoad
+	idPlate
+	idMeshedPlate
+	idPressure
+	idKinds
+)
+
+// idAllocator hands out increasing ids for each kind of object.  Ids are never
+// reused after an object is removed so references held by callers stay
+// unambiguous until the model is renumbered.
+type idAllocator struct {
+	last [idKinds]int
+}
+
+// next returns the lowest id above the last one given out that is not taken.
+// Checking taken keeps ids unique in models read from json or edited directly.
+func (a *idAllocator) next(kind int, taken func(int) bool) int {
+	id := a.last[kind] + 1
+	for taken(id) {
+		id++
+	}
+	a.last[kind] = id
+	return id
+}
+
+func (m *Skyciv) nextNodeId() int {
+	return m.ids.next(idNode, func(id int) bool { _, ok := m.Nodes[id]; return ok })
+}
+func (m *Skyciv) nextSectionId() int {
+	return m.ids.next(idSection, func(id int) bool { _, ok := m.Sections[id]; return ok })
+}
+func (m *Skyciv) nextMaterialId() int {
+	return m.ids.next(idMaterial, func(id int) bool { return m.Materials.ById(id) != nil })
+}
+func (m *Skyciv) nextSupportId() int {
+	return m.ids.next(idSupport, func(id int) bool { _, ok := m.Supports[id]; return ok })
+}
+func (m *Skyciv) nextAreaLoadId() int {
+	return m.ids.next(idAreaLoad, func(id int) bool { _, ok := m.AreaLoads[id]; return ok })
+}
+func (m *Skyciv) nextSelfWeightId() int {
+	return m.ids.next(idSelfWeight, func(id int) bool { _, ok := m.SelfWeight[id]; return ok })
+}
+func (m *Skyciv) nextPointLoadId() int {
+	return m.ids.next(idPointLoad, func(id int) bool { _, ok := m.PointLoads[id]; return ok })
+}
+func (m *Skyciv) nextMomentId() int {
+	return m.ids.next(idMoment, func(id int) bool { _, ok := m.Moments[id]; return ok })
+}
+func (m *Skyciv) nextDistributedLoadId() int {
+	return m.ids.next(idDistributedLoad, func(id int) bool { _, ok := m.DistributedLoads[id]; return ok })
+}
+func (m *Skyciv) nextPlateId() int {
+	return m.ids.next(idPlate, func(id int) bool { _, ok := m.Plates[id]; return ok })
+}
+func (m *Skyciv) nextMeshedPlateId() int {
+	return m.ids.next(idMeshedPlate, func(id int) bool { _, ok := m.MeshedPlates[id]; return ok })
+}
+func (m *Skyciv) nextPressureId() int {
+	return m.ids.next(idPressure, func(id int) bool { _, ok := m.Pressures[id]; return ok })
+}
+
+// compact maps each of the ids, in increasing order, to 1, 2, 3...
+func compact(ids []int) map[int]int {
+	sort.Ints(ids)
+	ret := make(map[int]int, len(ids))
+	for i, id := range ids {
+		ret[id] = i + 1
+	}
+	return ret
+}
+
+func (l StringIntList) renumber(ids map[int]int) {
+	for i, id := range l {
+		l[i] = ids[id]
+	}
+}
+
+// Renumber compacts the ids of every object in the model so they run from 1
+// without gaps, keeping their order.  Removing objects leaves gaps, which
+// skyciv accepts but are confusing to read, so renumber before exporting.
+func (m *Skyciv) Renumber() {
+	var keys []int
+	for id := range m.Nodes {
+		keys = append(keys, id)
+	}
+	nodeIds := compact(keys)
+	nodes := make(map[int]*Node, len(m.Nodes))
+	for id, n := range m.Nodes {
+		n.Id = nodeIds[id]
+		nodes[n.Id] = n
+	}
+	m.Nodes = nodes
+
+	keys = keys[:0]
+	for _, mat := range m.Materials {
+		keys = append(keys, mat.Id)
+	}
+	materialIds := compact(keys)
+	for _, mat := range m.Materials {
+		mat.Id = materialIds[mat.Id]
+	}
+
+	keys = keys[:0]
+	for id := range m.Sections {
+		keys = append(keys, id)
+	}
+	sectionIds := compact(keys)
+	sections := make(map[int]*Section, len(m.Sections))
+	for id, sec := range m.Sections {
+		sec.Id = sectionIds[id]
+		sec.MaterialId = materialIds[sec.MaterialId]
+		sections[sec.Id] = sec
+	}
+	m.Sections = sections
+
+	keys = keys[:0]
+	for id := range m.Supports {
+		keys = append(keys, id)
+	}
+	supportIds := compact(keys)
+	supports := make(map[int]*Support, len(m.Supports))
+	for id, sup := range m.Supports {
+		sup.Id = supportIds[id]
+		sup.Node = nodeIds[sup.Node]
+		supports[sup.Id] = sup
+	}
+	m.Supports = supports
+
+	keys = keys[:0]
+	for id := range m.AreaLoads {
+		keys = append(keys, id)
+	}
+	areaLoadIds := compact(keys)
+	areaLoads := make(map[int]*AreaLoad, len(m.AreaLoads))
+	for id, al := range m.AreaLoads {
+		al.Id = areaLoadIds[id]
+		al.Nodes.renumber(nodeIds)
+		al.ColumnDirection.renumber(nodeIds)
+		areaLoads[al.Id] = al
+	}
+	m.AreaLoads = areaLoads
+
+	keys = keys[:0]
+	for id := range m.SelfWeight {
+		keys = append(keys, id)
+	}
+	selfWeightIds := compact(keys)
+	selfWeight := make(map[int]*SelfWeight, len(m.SelfWeight))
+	for id, sw := range m.SelfWeight {
+		sw.Id = selfWeightIds[id]
+		selfWeight[sw.Id] = sw
+	}
+	m.SelfWeight = selfWeight
+
+	keys = keys[:0]
+	for id := range m.PointLoads {
+		keys = append(keys, id)
+	}
+	pointLoadIds := compact(keys)
+	pointLoads := make(map[int]*PointLoad, len(m.PointLoads))
+	for id, pl := range m.PointLoads {
+		pl.Id = pointLoadIds[id]
+		pointLoads[pl.Id] = pl
+	}
+	m.PointLoads = pointLoads
+
+	keys = keys[:0]
+	for id := range m.Moments {
+		keys = append(keys, id)
+	}
+	momentIds := compact(keys)
+	moments := make(map[int]*Moment, len(m.Moments))
+	for id, mo := range m.Moments {
+		mo.Id = momentIds[id]
+		moments[mo.Id] = mo
+	}
+	m.Moments = moments
+
+	keys = keys[:0]
+	for id := range m.DistributedLoads {
+		keys = append(keys, id)
+	}
+	distributedIds := compact(keys)
+	distributed := make(DistributedLoadList, len(m.DistributedLoads))
+	for id, dl := range m.DistributedLoads {
+		dl.Id = distributedIds[id]
+		distributed[dl.Id] = dl
+	}
+	m.DistributedLoads = distributed
+
+	keys = keys[:0]
+	for id := range m.Plates {
+		keys = append(keys, id)
+	}
+	plateIds := compact(keys)
+	plates := make(map[int]*Plate, len(m.Plates))
+	for id, p := range m.Plates {
+		p.Id = plateIds[id]
+		p.Nodes.renumber(nodeIds)
+		p.MaterialId = materialIds[p.MaterialId]
+		plates[p.Id] = p
+	}
+	m.Plates = plates
+
+	keys = keys[:0]
+	for id := range m.MeshedPlates {
+		keys = append(keys, id)
+	}
+	meshedIds := compact(keys)
+	meshed := make(map[int]*MeshedPlate, len(m.MeshedPlates))
+	for id, mp := range m.MeshedPlates {
+		mp.Id = meshedIds[id]
+		mp.NodeA, mp.NodeB = nodeIds[mp.NodeA], nodeIds[mp.NodeB]
+		mp.NodeC, mp.NodeD = nodeIds[mp.NodeC], nodeIds[mp.NodeD]
+		mp.Parent = plateIds[mp.Parent]
+		meshed[mp.Id] = mp
+	}
+	m.MeshedPlates = meshed
+
+	keys = keys[:0]
+	for id := range m.Pressures {
+		keys = append(keys, id)
+	}
+	pressureIds := compact(keys)
+	pressures := make(map[int]*Pressure, len(m.Pressures))
+	for id, pr := range m.Pressures {
+		pr.Id = pressureIds[id]
+		pr.PlateId = plateIds[pr.PlateId]
+		pressures[pr.Id] = pr
+	}
+	m.Pressures = pressures
+
+	m.ids = idAllocator{}
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"math"
+)
+
+const (
+	// colocation tolerance used by Node.Colocated
+	// TODO: precision of 3 assumed
+	colocatedEps = 0.001
+
+	// size of the cells of the node index in model length units
+	indexCellSize = 1.
+)
+
+type cellKey [3]int64
+
+// nodeIndex is a spatial hash of the nodes of a model so nodes can be found
+// by position without scanning every node
+type nodeIndex struct {
+	cells    map[cellKey][]*Node
+	count    int
+	min, max cellKey
+}
+
+func cellOf(x, y, z float64) cellKey {
+	return cellKey{
+		int64(math.Floor(x / indexCellSize)),
+		int64(math.Floor(y / indexCellSize)),
+		int64(math.Floor(z / indexCellSize)),
+	}
+}
+
+func newNodeIndex(nodes map[int]*Node) *nodeIndex {
+	idx := &nodeIndex{cells: make(map[cellKey][]*Node)}
+	for _, n := range nodes {
+		idx.add(n)
+	}
+	return idx
+}
+
+func (idx *nodeIndex) add(n *Node) {
+	k := cellOf(n.X, n.Y, n.Z)
+	if idx.count == 0 {
+		idx.min, idx.max = k, k
+	}
+	for i := 0; i < 3; i++ {
+		if k[i] < idx.min[i] {
+			idx.min[i] = k[i]
+		}
+		if k[i] > idx.max[i] {
+			idx.max[i] = k[i]
+		}
+	}
+	idx.cells[k] = append(idx.cells[k], n)
+	idx.count++
+}
+
+func (idx *nodeIndex) remove(n *Node) {
+	k := cellOf(n.X, n.Y, n.Z)
+	cell := idx.cells[k]
+	for i, p := range cell {
+		if p == n {
+			idx.cells[k] = append(cell[:i], cell[i+1:]...)
+			idx.count--
+			return
+		}
+	}
+}
+
+// colocated finds a node within the colocation tolerance of the point.  The
+// tolerance box can straddle at most two cells along each axis.
+func (idx *nodeIndex) colocated(x, y, z float64) *Node {
+	lo := cellOf(x-colocatedEps, y-colocatedEps, z-colocatedEps)
+	hi := cellOf(x+colocatedEps, y+colocatedEps, z+colocatedEps)
+
+	for i := lo[0]; i <= hi[0]; i++ {
+		for j := lo[1]; j <= hi[1]; j++ {
+			for k := lo[2]; k <= hi[2]; k++ {
+				for _, n := range idx.cells[cellKey{i, j, k}] {
+					if n.Colocated(x, y, z) {
+						return n
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// nearest searches shells of cells outward from the point until no closer
+// node can be in the next shell.  Points far from every node would visit more
+// empty cells than there are nodes, so those scan the nodes instead.
+func (idx *nodeIndex) nearest(x, y, z float64) (minNode *Node) {
+	if idx.count == 0 {
+		return nil
+	}
+
+	c := cellOf(x, y, z)
+	p := Vector{x, y, z}
+	minDistance := math.MaxFloat64
+
+	// the furthest shell that could hold a node
+	var maxShell int64
+	for i := 0; i < 3; i++ {
+		maxShell = max64(maxShell, max64(c[i]-idx.min[i], idx.max[i]-c[i]))
+	}
+
+	visit := func(n *Node) {
+		if d := n.ToVector().Diff(p).Length(); d < minDistance {
+			minDistance = d
+			minNode = n
+		}
+	}
+
+	for r := int64(0); r <= maxShell; r++ {
+		// every point in shell r is at least (r-1) cells away
+		if minNode != nil && float64(r-1)*indexCellSize > minDistance {
+			break
+		}
+		if side := 2*r + 1; side*side*side > int64(idx.count) {
+			for _, cell := range idx.cells {
+				for _, n := range cell {
+					visit(n)
+				}
+			}
+			break
+		}
+		idx.shell(c, r, visit)
+	}
+	return
+}
+
+// shell calls fn with every node in the cells exactly r cells from c
+func (idx *nodeIndex) shell(c cellKey, r int64, fn func(*Node)) {
+	for i := c[0] - r; i <= c[0]+r; i++ {
+		for j := c[1] - r; j <= c[1]+r; j++ {
+			onFace := i == c[0]-r || i == c[0]+r || j == c[1]-r || j == c[1]+r
+			step := int64(1)
+			if !onFace && r > 0 {
+				// only the near and far cells of interior columns are on the shell
+				step = 2 * r
+			}
+			for k := c[2] - r; k <= c[2]+r; k += step {
+				for _, n := range idx.cells[cellKey{i, j, k}] {
+					fn(n)
+				}
+			}
+		}
+	}
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// index returns the node index, rebuilding it if nodes were added to or
+// removed from m.Nodes directly
+func (m *Skyciv) index() *nodeIndex {
+	if m.nodeIndex == nil || m.nodeIndex.count != len(m.Nodes) {
+		m.nodeIndex = newNodeIndex(m.Nodes)
+	}
+	return m.nodeIndex
+}
+
+// addNode gives n the next id and adds it to the model and the index
+func (m *Skyciv) addNode(n *Node) *Node {
+	idx := m.index()
+	n.model = m
+	n.Id = m.nextNodeId()
+	m.Nodes[n.Id] = n
+	idx.add(n)
+	return n
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFindNearestNode(T *testing.T) {
+	m := NewModel(nil)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		m.NewNode(r.Float64()*40-20, r.Float64()*10, r.Float64()*40-20)
+	}
+
+	for i := 0; i < 200; i++ {
+		x, y, z := r.Float64()*60-30, r.Float64()*20-5, r.Float64()*60-30
+
+		var want *Node
+		best := math.MaxFloat64
+		for _, n := range m.Nodes {
+			if d := n.ToVector().Diff(Vector{x, y, z}).Length(); d < best {
+				best, want = d, n
+			}
+		}
+		if got := m.FindNearestNode(x, y, z); got != want {
+			T.Fatalf("nearest to %f,%f,%f is node %d not %d", x, y, z, got.Id, want.Id)
+		}
+	}
+}
+
+func TestColocatedAcrossCells(T *testing.T) {
+	m := NewModel(nil)
+	n := m.NewNode(0.9999, 2, -1.0004)
+	if p := m.NewNode(1.0003, 2.0002, -0.9998); p != n {
+		T.Errorf("colocated node on the other side of a cell boundary was duplicated")
+	}
+	if p := m.NewNode(1.0011, 2, -1); p == n {
+		T.Errorf("node outside the tolerance was merged")
+	}
+}
+
+// dome adds the nodes of a hemisphere of struts with the given number of
+// rings, each node shared by several struts like a geodesic dome
+func dome(m *Skyciv, rings int) {
+	const radius = 50.
+	for i := 0; i <= rings; i++ {
+		phi := math.Pi / 2 * float64(i) / float64(rings)
+		count := 6 * (i + 1)
+		for j := 0; j < count; j++ {
+			theta := 2 * math.Pi * float64(j) / float64(count)
+			x := radius * math.Cos(phi) * math.Cos(theta)
+			y := radius * math.Sin(phi)
+			z := radius * math.Cos(phi) * math.Sin(theta)
+			// every node is visited again as the end of a neighboring strut
+			m.NewNode(x, y, z)
+			m.NewNode(x, y, z)
+		}
+	}
+}
+
+func BenchmarkNewNode(B *testing.B) {
+	for i := 0; i < B.N; i++ {
+		dome(NewModel(nil), 60)
+	}
+}
+
+// BenchmarkFindNearestNode looks up points near the nodes the way frame
+// builders find the corners of area loads
+func BenchmarkFindNearestNode(B *testing.B) {
+	m := NewModel(nil)
+	dome(m, 60)
+	nodes := make([]*Node, 0, len(m.Nodes))
+	for _, n := range m.Nodes {
+		nodes = append(nodes, n)
+	}
+	r := rand.New(rand.NewSource(1))
+	B.ResetTimer()
+	for i := 0; i < B.N; i++ {
+		n := nodes[i%len(nodes)]
+		m.FindNearestNode(n.X+r.Float64()-0.5, n.Y+r.Float64()-0.5, n.Z+r.Float64()-0.5)
+	}
+}
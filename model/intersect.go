@@ -0,0 +1,169 @@
+package model
+
+import (
+	"math"
+)
+
+// closestPoints finds the parameters s along p0-p1 and t along q0-q1, both in
+// [0, 1], of the closest points between the two segments.  ok is false if the
+// segments are parallel, which leaves the closest points ambiguous.
+func closestPoints(p0, p1, q0, q1 Vector) (s, t float64, ok bool) {
+	d1 := p1.Diff(p0)
+	d2 := q1.Diff(q0)
+	r := p0.Diff(q0)
+	a := d1.Dot(d1)
+	e := d2.Dot(d2)
+	f := d2.Dot(r)
+	c := d1.Dot(r)
+	b := d1.Dot(d2)
+
+	denom := a*e - b*b
+	if denom <= 1e-12*a*e {
+		return 0, 0, false
+	}
+
+	clamp := func(v float64) float64 { return math.Max(0, math.Min(1, v)) }
+
+	s = clamp((b*f - c*e) / denom)
+	t = (b*s + f) / e
+	if t < 0 || t > 1 {
+		t = clamp(t)
+		s = clamp((b*t - c) / a)
+	}
+	return s, t, true
+}
+
+func lerp(a, b Vector, t float64) Vector {
+	return a.Sum(b.Diff(a).Scale(t))
+}
+
+// nodeNear finds the node of the member closest to p if it is within tol
+func (mem *ContinuousMember) nodeNear(p Vector, tol float64) *Node {
+	var best *Node
+	bestDistance := tol
+	for _, n := range mem.nodes {
+		if d := n.ToVector().Diff(p).Length(); d <= bestDistance {
+			best, bestDistance = n, d
+		}
+	}
+	return best
+}
+
+// connectAt returns the node of the member at p, splitting the member if no
+// node is within tol
+func (mem *ContinuousMember) connectAt(p Vector, tol float64) (*Node, error) {
+	if n := mem.nodeNear(p, tol); n != nil {
+		return n, nil
+	}
+	return mem.SplitAt(p.X, p.Y, p.Z)
+}
+
+// ConnectIntersections finds members that cross or touch within tol of each
+// other and connects them with a shared node, splitting either member where
+// it has no node near the intersection.  Parallel members are never connected.
+// It returns the number of intersections that were connected.
+func (m *Skyciv) ConnectIntersections(tol float64) (int, error) {
+	mems := m.ContinuousMembers.members
+	count := 0
+
+	for i, a := range mems {
+		for _, b := range mems[i+1:] {
+			s, t, ok := closestPoints(a.Begin().ToVector(), a.End().ToVector(), b.Begin().ToVector(), b.End().ToVector())
+			if !ok {
+				continue
+			}
+			pa := lerp(a.Begin().ToVector(), a.End().ToVector(), s)
+			pb := lerp(b.Begin().ToVector(), b.End().ToVector(), t)
+			if pa.Diff(pb).Length() > tol {
+				continue
+			}
+			if n := a.nodeNear(pa, tol); n != nil && n == b.nodeNear(pb, tol) {
+				continue
+			}
+
+			na, err := a.connectAt(pa, tol)
+			if err != nil {
+				return count, err
+			}
+			nb, err := b.connectAt(pb, tol)
+			if err != nil {
+				return count, err
+			}
+			if na != nb {
+				// keep the older node so ids given out earlier stay valid
+				if nb.Id < na.Id {
+					na, nb = nb, na
+				}
+				m.mergeNodes(na, nb)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// mergeNodes replaces every reference to drop with keep and removes drop.  A
+// support on drop is moved to keep unless keep is already supported.
+func (m *Skyciv) mergeNodes(keep, drop *Node) {
+	for _, mem := range m.ContinuousMembers.members {
+		nodes := mem.nodes[:0]
+		for _, n := range mem.nodes {
+			if n == drop {
+				n = keep
+			}
+			// the member may already have passed through keep
+			if len(nodes) > 0 && nodes[len(nodes)-1] == n {
+				continue
+			}
+			nodes = append(nodes, n)
+		}
+		mem.nodes = nodes
+	}
+
+	if sup := drop.support; sup != nil {
+		if keep.support == nil {
+			sup.Node = keep.Id
+			keep.support = sup
+		} else {
+			delete(m.Supports, sup.Id)
+		}
+		drop.support = nil
+	}
+
+	for _, pl := range m.PointLoads {
+		if pl.Node == drop {
+			pl.Node = keep
+		}
+	}
+	for _, mo := range m.Moments {
+		if mo.Node == drop {
+			mo.Node = keep
+		}
+	}
+
+	replace := func(ids []int) {
+		for i, id := range ids {
+			if id == drop.Id {
+				ids[i] = keep.Id
+			}
+		}
+	}
+	for _, al := range m.AreaLoads {
+		replace(al.Nodes)
+		replace(al.ColumnDirection)
+	}
+	for _, p := range m.Plates {
+		replace(p.Nodes)
+	}
+	for _, mp := range m.MeshedPlates {
+		for _, id := range []*int{&mp.NodeA, &mp.NodeB, &mp.NodeC, &mp.NodeD} {
+			if *id == drop.Id {
+				*id = keep.Id
+			}
+		}
+	}
+
+	m.index().remove(drop)
+	delete(m.Nodes, drop.Id)
+	drop.model = nil
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestConnectIntersections(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "test")
+
+	post := m.NewContinuousMember(sec, 0, 0, 0, 0, 10, 0)
+	// a tie crossing the post and a girt ending against it
+	tie := m.NewContinuousMember(sec, -2, 8, 0, 2, 8, 0)
+	girt := m.NewContinuousMember(sec, 0, 4, 0, 0, 4, 6)
+	// a brace that misses the post by less than the tolerance
+	brace := m.NewContinuousMember(sec, -1, 2, 0.005, 1, 2, 0.005)
+	// parallel to the post and never connected
+	m.NewContinuousMember(sec, 1.5, 0, 0, 1.5, 10, 0)
+	// too far from anything
+	far := m.NewContinuousMember(sec, -1, 6, 1, 1, 6, 1)
+
+	nodes := len(m.Nodes)
+	n, err := m.ConnectIntersections(0.01)
+	if err != nil {
+		T.Fatal(err)
+	}
+	// tie and post, girt and post, brace and post, tie and the parallel post
+	if n != 4 {
+		T.Errorf("connected %d intersections instead of 4", n)
+	}
+
+	shares := func(a, b *ContinuousMember) bool {
+		for _, p := range a.Nodes() {
+			if b.uses(p) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range []*ContinuousMember{tie, girt, brace} {
+		if !shares(post, c) {
+			T.Errorf("member not connected to the post")
+		}
+	}
+	if shares(post, far) || len(far.Nodes()) != 2 {
+		T.Errorf("member far from the post was connected")
+	}
+	if !girt.Begin().Colocated(0, 4, 0) || len(girt.Nodes()) != 2 {
+		T.Errorf("girt end moved or split")
+	}
+	if len(post.Nodes()) != 5 {
+		T.Errorf("post has %d nodes instead of 5", len(post.Nodes()))
+	}
+
+	// the post reuses the end of the girt and the node split from the brace is
+	// merged into the post, leaving two on the post and one on the other post
+	if added := len(m.Nodes) - nodes; added != 3 {
+		T.Errorf("connecting added %d nodes instead of 3", added)
+	}
+	for id, n := range m.Nodes {
+		if n.Id != id {
+			T.Errorf("node %d stored under id %d", n.Id, id)
+		}
+	}
+
+	if n, _ := m.ConnectIntersections(0.01); n != 0 {
+		T.Errorf("connected %d intersections a second time", n)
+	}
+}
@@ -2,13 +2,27 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 )
 
+// CaseMapping assigns load groups to the load categories combined by each
+// Case.  Live is floor live load, roof live load from workers and equipment
+// is RoofLive.
 type CaseMapping struct {
-	Dead []string
-	Live []string
-	Snow []string
-	Wind []string
+	Dead     []string
+	Live     []string
+	RoofLive []string
+	Snow     []string
+	Wind     []string
+	Seismic  []string
+	// SnowPatterns are arrangements of the snow groups that are never applied
+	// together, such as balanced and unbalanced snow
+	SnowPatterns [][]string
+	// Other are load groups of no category, such as those of skyciv models
+	// named "LG1" or "Crane", whose factors are kept by each Case
+	Other []string
 }
 
 func (c *CaseMapping) DeadCases(loadGroups ...string) *CaseMapping {
@@ -21,8 +35,24 @@ func (c *CaseMapping) LiveCases(loadGroups ...string) *CaseMapping {
 	return c
 }
 
+func (c *CaseMapping) RoofLiveCases(loadGroups ...string) *CaseMapping {
+	c.RoofLive = loadGroups
+	return c
+}
+
 func (c *CaseMapping) SnowCases(loadGroups ...string) *CaseMapping {
 	c.Snow = loadGroups
+	c.SnowPatterns = nil
+	return c
+}
+
+// SnowPatternCases maps every group of the patterns to snow
+func (c *CaseMapping) SnowPatternCases(patterns ...[]string) *CaseMapping {
+	c.Snow = nil
+	for _, p := range patterns {
+		c.Snow = append(c.Snow, p...)
+	}
+	c.SnowPatterns = patterns
 	return c
 }
 
@@ -31,12 +61,128 @@ func (c *CaseMapping) WindCases(loadGroups ...string) *CaseMapping {
 	return c
 }
 
+func (c *CaseMapping) SeismicCases(loadGroups ...string) *CaseMapping {
+	c.Seismic = loadGroups
+	return c
+}
+
+// Groups lists every mapped load group
+func (c *CaseMapping) Groups() (ret []string) {
+	for _, gs := range [][]string{c.Dead, c.Live, c.RoofLive, c.Snow, c.Wind, c.Seismic, c.Other} {
+		ret = append(ret, gs...)
+	}
+	return
+}
+
+// Factors finds the factor of each mapped load group in the case
+func (c *CaseMapping) Factors(ca Case) map[string]float64 {
+	ret := make(map[string]float64)
+	add := func(groups []string, f float64) {
+		for _, g := range groups {
+			ret[g] += f
+		}
+	}
+	add(c.Dead, ca.Dead)
+	add(c.Live, ca.Live)
+	add(c.RoofLive, ca.RoofLive)
+	if len(ca.SnowGroups) > 0 {
+		add(ca.SnowGroups, ca.Snow)
+	} else {
+		add(c.Snow, ca.Snow)
+	}
+	if ca.WindGroup != "" {
+		add([]string{ca.WindGroup}, ca.Wind)
+	} else {
+		add(c.Wind, ca.Wind)
+	}
+	if ca.SeismicGroup != "" {
+		add([]string{ca.SeismicGroup}, ca.Seismic)
+	} else {
+		add(c.Seismic, ca.Seismic)
+	}
+	add(c.Other, 0)
+	for g, f := range ca.Other {
+		ret[g] = f
+	}
+	return ret
+}
+
 type Case struct {
-	Name string
-	Dead float64
-	Live float64
-	Snow float64
-	Wind float64
+	Name     string
+	Dead     float64
+	Live     float64
+	RoofLive float64
+	Snow     float64
+	Wind     float64
+	Seismic  float64
+	// WindGroup limits the wind load to one of the mapped wind groups, for
+	// wind loads that act in one direction at a time
+	WindGroup string
+	// SnowGroups limits the snow load to one of the mapped snow patterns
+	SnowGroups []string
+	// SeismicGroup limits the seismic load to one of the mapped seismic
+	// groups, for lateral forces in one direction at a time
+	SeismicGroup string
+	// Other are the factors of load groups that are not combined by category,
+	// which replace the factor of the group's category
+	Other map[string]float64
+}
+
+// splitGroups replaces each case with a factor for a category of load groups
+// by one case for each of the groups, which is stored in the field returned
+// by group
+func (a *Combination) splitGroups(groups []string, factor func(Case) float64, group func(*Case) *string) {
+	if len(groups) < 2 {
+		return
+	}
+	var cases []Case
+	for _, ca := range a.Cases {
+		if factor(ca) == 0 || *group(&ca) != "" {
+			cases = append(cases, ca)
+			continue
+		}
+		for _, g := range groups {
+			c := ca
+			c.Name = fmt.Sprintf("%s (%s)", ca.Name, g)
+			*group(&c) = g
+			cases = append(cases, c)
+		}
+	}
+	a.Cases = cases
+}
+
+// SplitWind replaces each case with wind load by one case for each mapped
+// wind group
+func (a *Combination) SplitWind() {
+	a.splitGroups(a.Mapping.Wind, func(c Case) float64 { return c.Wind }, func(c *Case) *string { return &c.WindGroup })
+}
+
+// SplitSeismic replaces each case with seismic load by one case for each
+// mapped seismic group
+func (a *Combination) SplitSeismic() {
+	a.splitGroups(a.Mapping.Seismic, func(c Case) float64 { return c.Seismic }, func(c *Case) *string { return &c.SeismicGroup })
+}
+
+// SplitSnow replaces each case with snow load by one case for each of the
+// mapped snow patterns
+func (a *Combination) SplitSnow() {
+	if len(a.Mapping.SnowPatterns) < 2 {
+		return
+	}
+	var cases []Case
+	for _, ca := range a.Cases {
+		if ca.Snow == 0 || len(ca.SnowGroups) > 0 {
+			cases = append(cases, ca)
+			continue
+		}
+		for _, p := range a.Mapping.SnowPatterns {
+			c := ca
+			c.Name = fmt.Sprintf("%s (%s)", ca.Name, strings.Join(p, ", "))
+			c.SnowGroups = p
+			cases = append(cases, c)
+		}
+	}
+	a.Cases = cases
 }
 
 type Combination struct {
@@ -51,21 +197,166 @@ func (a *Combination) MarshalJSON() ([]byte, error) {
 		l := make(map[string]interface{})
 
 		l["name"] = ca.Name
-		for _, m := range a.Mapping.Dead {
-			l[m] = ca.Dead
+		for g, f := range a.Mapping.Factors(ca) {
+			l[g] = f
 		}
-		for _, m := range a.Mapping.Live {
-			l[m] = ca.Live
+
+		combo[i+1] = l
+	}
+
+	return json.Marshal(combo)
+}
+
+// categoryOf guesses the case category of a load group from its name
+func categoryOf(group string) string {
+	g := strings.ToLower(group)
+	switch {
+	case strings.HasPrefix(g, "sw") || strings.Contains(g, "dead"):
+		return "dead"
+	case strings.Contains(g, "roof") && strings.Contains(g, "live"):
+		return "roof live"
+	case strings.Contains(g, "live"):
+		return "live"
+	case strings.Contains(g, "snow"):
+		return "snow"
+	case strings.Contains(g, "wind"):
+		return "wind"
+	case strings.Contains(g, "seismic") || strings.Contains(g, "quake"):
+		return "seismic"
+	}
+	return ""
+}
+
+// UnmarshalJSON rebuilds the mapping and cases from skyciv's per load group
+// factors.  Load groups are assigned a category by name, or failing that by
+// sharing every factor with a group that could be named, and are otherwise
+// mapped to Other.  A group whose factor in a case differs from that of its
+// category keeps its own factor in the Other of the case.
+func (a *Combination) UnmarshalJSON(b []byte) error {
+	combo := make(map[int]map[string]interface{})
+	if err := json.Unmarshal(b, &combo); err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(combo))
+	groupSet := make(map[string]bool)
+	for id, l := range combo {
+		ids = append(ids, id)
+		for k, v := range l {
+			if _, ok := v.(float64); ok {
+				groupSet[k] = true
+			}
 		}
-		for _, m := range a.Mapping.Snow {
-			l[m] = ca.Snow
+	}
+	sort.Ints(ids)
+
+	groups := make([]string, 0, len(groupSet))
+	for g := range groupSet {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	factor := func(id int, g string) float64 {
+		f, _ := combo[id][g].(float64)
+		return f
+	}
+	sameFactors := func(g, h string) bool {
+		for _, id := range ids {
+			if factor(id, g) != factor(id, h) {
+				return false
+			}
 		}
-		for _, m := range a.Mapping.Wind {
-			l[m] = ca.Wind
+		return true
+	}
+
+	category := make(map[string]string)
+	for _, g := range groups {
+		category[g] = categoryOf(g)
+	}
+	for _, g := range groups {
+		if category[g] != "" {
+			continue
+		}
+		for _, h := range groups {
+			if categoryOf(h) != "" && sameFactors(g, h) {
+				category[g] = categoryOf(h)
+				break
+			}
 		}
+	}
 
-		combo[i+1] = l
+	a.Mapping = CaseMapping{}
+	for _, g := range groups {
+		switch category[g] {
+		case "dead":
+			a.Mapping.Dead = append(a.Mapping.Dead, g)
+		case "live":
+			a.Mapping.Live = append(a.Mapping.Live, g)
+		case "roof live":
+			a.Mapping.RoofLive = append(a.Mapping.RoofLive, g)
+		case "snow":
+			a.Mapping.Snow = append(a.Mapping.Snow, g)
+		case "wind":
+			a.Mapping.Wind = append(a.Mapping.Wind, g)
+		case "seismic":
+			a.Mapping.Seismic = append(a.Mapping.Seismic, g)
+		default:
+			a.Mapping.Other = append(a.Mapping.Other, g)
+		}
 	}
 
-	return json.Marshal(combo)
+	first := func(id int, gs []string) float64 {
+		if len(gs) == 0 {
+			return 0
+		}
+		return factor(id, gs[0])
+	}
+
+	loadedGroups := func(id int, gs []string) (ret []string) {
+		for _, g := range gs {
+			if factor(id, g) != 0 {
+				ret = append(ret, g)
+			}
+		}
+		return
+	}
+
+	a.Cases = nil
+	for _, id := range ids {
+		name, _ := combo[id]["name"].(string)
+		ca := Case{
+			Name:     name,
+			Dead:     first(id, a.Mapping.Dead),
+			Live:     first(id, a.Mapping.Live),
+			RoofLive: first(id, a.Mapping.RoofLive),
+			Snow:     first(id, a.Mapping.Snow),
+			Wind:     first(id, a.Mapping.Wind),
+			Seismic:  first(id, a.Mapping.Seismic),
+		}
+		// a case that loads only one of several wind or seismic groups was
+		// split, and one that loads only some snow groups has a snow pattern
+		if loaded := loadedGroups(id, a.Mapping.Wind); len(loaded) == 1 && len(a.Mapping.Wind) > 1 {
+			ca.Wind = factor(id, loaded[0])
+			ca.WindGroup = loaded[0]
+		}
+		if loaded := loadedGroups(id, a.Mapping.Seismic); len(loaded) == 1 && len(a.Mapping.Seismic) > 1 {
+			ca.Seismic = factor(id, loaded[0])
+			ca.SeismicGroup = loaded[0]
+		}
+		if loaded := loadedGroups(id, a.Mapping.Snow); len(loaded) > 0 && len(loaded) < len(a.Mapping.Snow) {
+			ca.Snow = factor(id, loaded[0])
+			ca.SnowGroups = loaded
+		}
+		combined := a.Mapping.Factors(ca)
+		for _, g := range groups {
+			if f := factor(id, g); f != combined[g] {
+				if ca.Other == nil {
+					ca.Other = make(map[string]float64)
+				}
+				ca.Other[g] = f
+			}
+		}
+		a.Cases = append(a.Cases, ca)
+	}
+	return nil
 }
@@ -0,0 +1,79 @@
+package model
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SawnLumberLibrary is the skyciv library path of NDS sawn lumber sections,
+// which is followed by the nominal size
+var SawnLumberLibrary = []string{"American", "NDS", "Sawn Lumber"}
+
+//go:embed sawn_lumber.csv
+var sawnLumberCSV string
+
+var (
+	sawnLumberOnce  sync.Once
+	sawnLumberSizes map[string][2]float64
+)
+
+// readSawnLumber parses the embedded table of dressed sizes once
+func readSawnLumber() map[string][2]float64 {
+	sawnLumberOnce.Do(func() {
+		r := csv.NewReader(strings.NewReader(sawnLumberCSV))
+		r.Comment = '#'
+		rows, err := r.ReadAll()
+		if err != nil {
+			panic(fmt.Errorf("sawn lumber table: %v", err))
+		}
+		sawnLumberSizes = make(map[string][2]float64)
+		for _, row := range rows[1:] {
+			b, errB := strconv.ParseFloat(row[1], 64)
+			d, errD := strconv.ParseFloat(row[2], 64)
+			if errB != nil || errD != nil {
+				panic(fmt.Errorf("sawn lumber table: bad size %v", row))
+			}
+			sawnLumberSizes[row[0]] = [2]float64{b, d}
+		}
+	})
+	return sawnLumberSizes
+}
+
+// SawnLumberSize finds the dressed breadth and depth in in of a nominal size
+// of sawn lumber, such as "8 x 10"
+func SawnLumberSize(nominal string) (breadth, depth float64, err error) {
+	size, ok := readSawnLumber()[nominal]
+	if !ok {
+		return 0, 0, fmt.Errorf("%q is not a standard size of sawn lumber", nominal)
+	}
+	return size[0], size[1], nil
+}
+
+// NominalSize is the nominal size at the end of the library path of the
+// section
+func (s *Section) NominalSize() string {
+	if len(s.LoadSection) == 0 {
+		return ""
+	}
+	return s.LoadSection[len(s.LoadSection)-1]
+}
+
+// NewSawnLumberSection adds an NDS sawn lumber library section of the nominal
+// size, such as "8 x 10", that also carries the properties of its dressed size
+// in in for local analysis.  The depth is along the local y axis.
+func (m *Skyciv) NewSawnLumberSection(material *Material, nominal string) (*Section, error) {
+	b, d, err := SawnLumberSize(nominal)
+	if err != nil {
+		return nil, err
+	}
+	s := m.NewSectionFromLibrary(material, append(append([]string(nil), SawnLumberLibrary...), nominal)...)
+	s.Area = b * d
+	s.Iz = b * d * d * d / 12
+	s.Iy = d * b * b * b / 12
+	s.J = torsionConstant(b, d)
+	return s, nil
+}
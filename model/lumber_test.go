@@ -0,0 +1,49 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSawnLumberSection(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("test")
+
+	for _, c := range []struct {
+		nominal string
+		b, d    float64
+	}{
+		{"2 x 4", 1.5, 3.5},
+		{"4 x 8", 3.5, 7.25},
+		{"8 x 10", 7.5, 9.5},
+		{"6 x 6", 5.5, 5.5},
+	} {
+		sec, err := m.NewSawnLumberSection(mat, c.nominal)
+		if err != nil {
+			T.Fatal(err)
+		}
+		if sec.NominalSize() != c.nominal || len(sec.LoadSection) != 4 {
+			T.Errorf("%s has library path %v", c.nominal, sec.LoadSection)
+		}
+		if sec.Area != c.b*c.d {
+			T.Errorf("%s has area %f instead of %f", c.nominal, sec.Area, c.b*c.d)
+		}
+		if iz := c.b * math.Pow(c.d, 3) / 12; math.Abs(sec.Iz-iz) > 1e-9 {
+			T.Errorf("%s has Iz %f instead of %f", c.nominal, sec.Iz, iz)
+		}
+		if iy := c.d * math.Pow(c.b, 3) / 12; math.Abs(sec.Iy-iy) > 1e-9 {
+			T.Errorf("%s has Iy %f instead of %f", c.nominal, sec.Iy, iy)
+		}
+		// J of a square is 0.1406 b^4, which the approximation is close to
+		if c.b == c.d && math.Abs(sec.J-0.1406*math.Pow(c.b, 4)) > 0.005*sec.J {
+			T.Errorf("%s has J %f instead of %f", c.nominal, sec.J, 0.1406*math.Pow(c.b, 4))
+		}
+		if sec.J <= 0 || sec.J >= sec.Iy+sec.Iz {
+			T.Errorf("%s has J %f", c.nominal, sec.J)
+		}
+	}
+
+	if _, err := m.NewSawnLumberSection(mat, "7 x 9"); err == nil {
+		T.Errorf("nonstandard size accepted")
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/donniet/goframes/units"
 )
 
 type Auth struct {
@@ -31,36 +35,26 @@ type Request struct {
 	Functions []Function `json:"functions"`
 }
 
-type Units struct {
-	Length           string `json:"length"`
-	SectionLength    string `json:"section_length"`
-	MaterialStrength string `json:"material_strength"`
-	Density          string `json:"density"`
-	Force            string `json:"force"`
-	Moment           string `json:"moment"`
-	Pressure         string `json:"pressure"`
-	Mass             string `json:"mass"`
-	Translation      string `json:"translation"`
-	Stress           string `json:"stress"`
-	Name             string `json:"-"`
-}
+// MemberOffsetsGlobal is the member_offsets_axis of models whose member
+// offsets are along the global axes, as OffsetA and OffsetB are
+const MemberOffsetsGlobal = "global"
 
 type Settings struct {
-	Units                                interface{} `json:"units,omitempty"`
-	Precision                            string      `json:"precision,omitempty"`
-	PrecisionValues                      int         `json:"precision_values,omitempty"`
-	EvaluationPoints                     int         `json:"evaluation_points,omitempty"`
-	VerticalAxis                         string      `json:"vertical_axis,omitempty"`
-	MemberOffsetsAxis                    string      `json:"member_offsets_axis,omitempty"`
-	ProjectionSystem                     string      `json:"projection_system,omitempty"`
-	SolverTimeout                        int         `json:"solver_timeout,omitempty"`
-	AccurateBucklingShape                *bool       `json:"accurate_buckling_shape,omitempty"`
-	BucklingJohnson                      *bool       `json:"buckling_johnson,omitempty"`
-	NonLinearTolerance                   string      `json:"non_linear_tolerance,omitempty"`
-	NonLinearTheory                      string      `json:"small,omitempty"`
-	AutoStabilizeModel                   *bool       `json:"auto_stabilize_model,omitempty"`
-	OnlySolveUserDefinedLoadCombinations *bool       `json:"only_solve_user_defined_load_combinations,omitempty"`
-	IncludeRigidLinksForRealAreaLoads    *bool       `json:"include_rigid_links_for_area_loads,omitempty"`
+	Units                                *units.System `json:"units,omitempty"`
+	Precision                            string        `json:"precision,omitempty"`
+	PrecisionValues                      int           `json:"precision_values,omitempty"`
+	EvaluationPoints                     int           `json:"evaluation_points,omitempty"`
+	VerticalAxis                         string        `json:"vertical_axis,omitempty"`
+	MemberOffsetsAxis                    string        `json:"member_offsets_axis,omitempty"`
+	ProjectionSystem                     string        `json:"projection_system,omitempty"`
+	SolverTimeout                        int           `json:"solver_timeout,omitempty"`
+	AccurateBucklingShape                *bool         `json:"accurate_buckling_shape,omitempty"`
+	BucklingJohnson                      *bool         `json:"buckling_johnson,omitempty"`
+	NonLinearTolerance                   string        `json:"non_linear_tolerance,omitempty"`
+	NonLinearTheory                      string        `json:"small,omitempty"`
+	AutoStabilizeModel                   *bool         `json:"auto_stabilize_model,omitempty"`
+	OnlySolveUserDefinedLoadCombinations *bool         `json:"only_solve_user_defined_load_combinations,omitempty"`
+	IncludeRigidLinksForRealAreaLoads    *bool         `json:"include_rigid_links_for_area_loads,omitempty"`
 }
 
 type Details struct{}
@@ -91,6 +85,14 @@ func (v Vector) Dot(w Vector) float64 {
 	return v.X*w.X + v.Y*w.Y + v.Z*w.Z
 }
 
+func (v Vector) Cross(w Vector) Vector {
+	return Vector{
+		X: v.Y*w.Z - v.Z*w.Y,
+		Y: v.Z*w.X - v.X*w.Z,
+		Z: v.X*w.Y - v.Y*w.X,
+	}
+}
+
 func (A Vector) Diff(B Vector) (v Vector) {
 	v.X = A.X - B.X
 	v.Y = A.Y - B.Y
@@ -126,27 +128,33 @@ func (n *Node) ToVector() (v Vector) {
 	return
 }
 
-func (n *Node) FixedSupport() {
-	if n.support != nil {
-		return
-	}
-
-	s := &Support{
-		DirectionCode: "BBBBBB",
-		Node:          n.Id,
-		RestraintCode: "FFFFFF",
-		Id:            len(n.model.Supports) + 1,
-	}
-	n.support = s
-	n.model.Supports[s.Id] = s
+func (n *Node) FixedSupport() *Support {
+	return n.NewSupport(RestraintFixed)
 }
 
+// Fixity codes for the ends of a member.  Each letter fixes (F) or releases (R)
+// the member end in local Fx, Fy, Fz, Mx, My and Mz.
+const (
+	FixityFixed     = "FFFFFF"
+	FixityPinned    = "FFFFRR"
+	FixityReleaseMy = "FFFFRF"
+	FixityReleaseMz = "FFFFFR"
+)
+
 type ContinuousMember struct {
-	nodes   []*Node
-	section *Section
-	// TODO: add offsets and end fixity's
+	nodes         []*Node
+	section       *Section
 	model         *Skyciv
 	RotationAngle float64
+
+	// FixityA and FixityB are the fixity codes at Begin and End.  The
+	// segments within the member are always rigidly connected.
+	FixityA string
+	FixityB string
+	// OffsetA and OffsetB move the ends of the member away from Begin and End
+	// along the global axes
+	OffsetA Vector
+	OffsetB Vector
 }
 
 func (mem *ContinuousMember) Begin() *Node {
@@ -156,6 +164,22 @@ func (mem *ContinuousMember) End() *Node {
 	return mem.nodes[len(mem.nodes)-1]
 }
 
+// Nodes returns the nodes of the member in order from Begin to End
+func (mem *ContinuousMember) Nodes() []*Node {
+	return mem.nodes
+}
+
+func (mem *ContinuousMember) Section() *Section {
+	return mem.section
+}
+
+// Pin releases the bending moments at both ends of the member
+func (mem *ContinuousMember) Pin() *ContinuousMember {
+	mem.FixityA = FixityPinned
+	mem.FixityB = FixityPinned
+	return mem
+}
+
 func (mem *ContinuousMember) Split(distance float64) (*Node, error) {
 	rem := distance
 	last := mem.nodes[0]
@@ -269,36 +293,187 @@ func (mem *ContinuousMember) SplitAt(x, y, z float64) (*Node, error) {
 
 type ContinuousMemberList struct {
 	members []*ContinuousMember
+	// skyciv members read by UnmarshalJSON waiting to be linked to the model
+	pending []*Member
 }
 
 func (l *ContinuousMemberList) Append(c *ContinuousMember) {
 	l.members = append(l.members, c)
 }
 
-// TODO: allow for offsets and different fixities
-func (l *ContinuousMemberList) MarshalJSON() ([]byte, error) {
-	mems := make(map[int]*Member)
-	k := 1
+func (l *ContinuousMemberList) Members() []*ContinuousMember {
+	return l.members
+}
 
+// Segment is the part of a ContinuousMember between two consecutive nodes.  Each
+// segment is exported to skyciv as a separate member with the given Id.
+type Segment struct {
+	Id     int
+	Member *ContinuousMember
+	Index  int
+	A, B   *Node
+
+	FixityA, FixityB string
+	OffsetA, OffsetB Vector
+}
+
+// Segments lists every segment in the order they are exported to skyciv
+func (l *ContinuousMemberList) Segments() (ret []Segment) {
+	k := 1
 	for _, c := range l.members {
 		for i := 1; i < len(c.nodes); i++ {
-			A, B := c.nodes[i-1], c.nodes[i]
-			mems[k] = &Member{
-				Type:  "normal_continuous",
-				NodeA: A.Id, NodeB: B.Id,
-				SectionId:     c.section.Id,
-				FixityA:       "FFFFFF",
-				FixityB:       "FFFFFF",
-				Id:            k,
-				RotationAngle: c.RotationAngle,
+			s := Segment{
+				Id:      k,
+				Member:  c,
+				Index:   i - 1,
+				A:       c.nodes[i-1],
+				B:       c.nodes[i],
+				FixityA: FixityFixed,
+				FixityB: FixityFixed,
+			}
+			if i == 1 {
+				s.FixityA, s.OffsetA = c.fixityA(), c.OffsetA
 			}
+			if i == len(c.nodes)-1 {
+				s.FixityB, s.OffsetB = c.fixityB(), c.OffsetB
+			}
+			ret = append(ret, s)
 			k++
 		}
 	}
+	return
+}
+
+// LocalAxes are the member axes used by skyciv for a member from a to b.  x runs
+// along the member and y points up along the global Y axis, or -X for vertical
+// members, before the member is rotated about x by rotation degrees.
+func LocalAxes(a, b Vector, rotation float64) (x, y, z Vector) {
+	x = b.Diff(a)
+	x.Normalize()
+
+	up := Vector{Y: 1}
+	if math.Abs(x.Dot(up)) > 1-1e-9 {
+		up = Vector{X: -1}
+	}
+	y = up.Diff(x.Scale(x.Dot(up)))
+	y.Normalize()
+	z = x.Cross(y)
+
+	if rotation != 0 {
+		c, s := math.Cos(rotation*math.Pi/180), math.Sin(rotation*math.Pi/180)
+		y, z = y.Scale(c).Sum(z.Scale(s)), z.Scale(c).Diff(y.Scale(s))
+	}
+	return
+}
+
+func (mem *ContinuousMember) fixityA() string {
+	if mem.FixityA == "" {
+		return FixityFixed
+	}
+	return mem.FixityA
+}
+
+func (mem *ContinuousMember) fixityB() string {
+	if mem.FixityB == "" {
+		return FixityFixed
+	}
+	return mem.FixityB
+}
+
+func (l *ContinuousMemberList) MarshalJSON() ([]byte, error) {
+	mems := make(map[int]*Member)
+
+	for _, s := range l.Segments() {
+		mems[s.Id] = &Member{
+			Type:  "normal_continuous",
+			NodeA: s.A.Id, NodeB: s.B.Id,
+			SectionId:     s.Member.section.Id,
+			FixityA:       s.FixityA,
+			FixityB:       s.FixityB,
+			OffsetAx:      s.OffsetA.X,
+			OffsetAy:      s.OffsetA.Y,
+			OffsetAz:      s.OffsetA.Z,
+			OffsetBx:      s.OffsetB.X,
+			OffsetBy:      s.OffsetB.Y,
+			OffsetBz:      s.OffsetB.Z,
+			Id:            s.Id,
+			RotationAngle: s.Member.RotationAngle,
+		}
+	}
 
 	return json.Marshal(mems)
 }
 
+func (l *ContinuousMemberList) UnmarshalJSON(b []byte) error {
+	mems := make(map[int]*Member)
+	if err := json.Unmarshal(b, &mems); err != nil {
+		return err
+	}
+
+	l.members = nil
+	l.pending = make([]*Member, 0, len(mems))
+	for id, mem := range mems {
+		mem.Id = id
+		l.pending = append(l.pending, mem)
+	}
+	sort.Slice(l.pending, func(i, j int) bool { return l.pending[i].Id < l.pending[j].Id })
+	return nil
+}
+
+// continues reports whether the skyciv member next can be appended to the
+// continuous member c as another segment
+func (c *ContinuousMember) continues(next *Member, nodeA, nodeB *Node) bool {
+	if c.End() != nodeA || c.section.Id != next.SectionId || c.RotationAngle != next.RotationAngle {
+		return false
+	}
+	// releases and offsets can only be at the ends of a continuous member
+	if c.fixityB() != FixityFixed || c.OffsetB != (Vector{}) {
+		return false
+	}
+	if next.FixityA != FixityFixed || next.OffsetA() != (Vector{}) {
+		return false
+	}
+
+	dir := c.End().ToVector().Diff(c.Begin().ToVector())
+	nextDir := nodeB.ToVector().Diff(nodeA.ToVector())
+	dir.Normalize()
+	nextDir.Normalize()
+
+	return dir.Dot(nextDir) > 1-1e-9
+}
+
+// link rebuilds continuous members from the skyciv members read by
+// UnmarshalJSON.  Consecutive collinear members sharing a node, section and
+// rotation are joined into a single continuous member.
+func (l *ContinuousMemberList) link(m *Skyciv) error {
+	var last *ContinuousMember
+	for _, mem := range l.pending {
+		A, ok := m.Nodes[mem.NodeA]
+		if !ok {
+			return fmt.Errorf("member %d references missing node %d", mem.Id, mem.NodeA)
+		}
+		B, ok := m.Nodes[mem.NodeB]
+		if !ok {
+			return fmt.Errorf("member %d references missing node %d", mem.Id, mem.NodeB)
+		}
+		sec, ok := m.Sections[mem.SectionId]
+		if !ok {
+			return fmt.Errorf("member %d references missing section %d", mem.Id, mem.SectionId)
+		}
+
+		if last != nil && last.continues(mem, A, B) {
+			last.nodes = append(last.nodes, B)
+		} else {
+			last = m.NewContinuousMemberBetweenNodes(sec, A, B)
+			last.RotationAngle = mem.RotationAngle
+			last.FixityA, last.OffsetA = mem.FixityA, mem.OffsetA()
+		}
+		last.FixityB, last.OffsetB = mem.FixityB, mem.OffsetB()
+	}
+	l.pending = nil
+	return nil
+}
+
 type Member struct {
 	Type          string  `json:"type"`
 	CableLength   *int    `json:"cable_length"`
@@ -317,6 +492,14 @@ type Member struct {
 	Id            int     `json:"-"`
 }
 
+func (m *Member) OffsetA() Vector {
+	return Vector{m.OffsetAx, m.OffsetAy, m.OffsetAz}
+}
+
+func (m *Member) OffsetB() Vector {
+	return Vector{m.OffsetBx, m.OffsetBy, m.OffsetBz}
+}
+
 type Quadrant int
 
 const (
@@ -329,6 +512,9 @@ const (
 func (q Quadrant) FirstPositive() bool  { return q&0x2 == 0 }
 func (q Quadrant) SecondPositive() bool { return q&0x1 == 0 }
 
+// Brace adds a brace between m and against, distance away from the node they
+// share in the given quadrant.  Braces are pinned at both ends, set FixityA
+// and FixityB on the returned member to change that.
 func (m *ContinuousMember) Brace(against *ContinuousMember, sec *Section, distance float64, quadrant Quadrant) (*ContinuousMember, error) {
 	// first find the node where they meet
 	var inter *Node
@@ -359,7 +545,7 @@ searchLoop:
 	} else if n1, err := against.SplitFrom(inter, d1); err != nil {
 		return nil, err
 	} else {
-		return m.model.NewContinuousMemberBetweenNodes(m.section, n0, n1), nil
+		return m.model.NewContinuousMemberBetweenNodes(sec, n0, n1).Pin(), nil
 	}
 }
 
@@ -367,9 +553,6 @@ var (
 	ErrColocated = errors.New("Colocated with Node")
 )
 
-type Plate struct{}
-type MeshedPlate struct{}
-
 type PointsCalc struct {
 	X    int
 	Y    int
@@ -445,6 +628,16 @@ type Material struct {
 
 type MaterialSet map[string]*Material
 
+// ById finds the material with the given id or nil if there isn't one
+func (ms MaterialSet) ById(id int) *Material {
+	for _, m := range ms {
+		if m.Id == id {
+			return m
+		}
+	}
+	return nil
+}
+
 func (ms MaterialSet) MarshalJSON() ([]byte, error) {
 	out := make(map[int]*Material)
 	for _, m := range ms {
@@ -453,6 +646,19 @@ func (ms MaterialSet) MarshalJSON() ([]byte, error) {
 	return json.Marshal(out)
 }
 
+func (ms *MaterialSet) UnmarshalJSON(b []byte) error {
+	in := make(map[int]*Material)
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*ms = make(MaterialSet)
+	for id, m := range in {
+		m.Id = id
+		(*ms)[m.Name] = m
+	}
+	return nil
+}
+
 func torsionConstant(breadth, depth float64) float64 {
 	a := depth / 2
 	b := breadth / 2
@@ -469,7 +675,7 @@ func torsionConstant(breadth, depth float64) float64 {
 func (m *Skyciv) NewSectionFromLibrary(material *Material, path ...string) *Section {
 	s := &Section{
 		MaterialId:  material.Id,
-		Id:          len(m.Sections) + 1,
+		Id:          m.nextSectionId(),
 		LoadSection: path,
 	}
 	m.Sections[s.Id] = s
@@ -562,12 +768,19 @@ func (s *Skyciv) NewContinuousMemberBetweenNodes(sec *Section, n0, n1 *Node) *Co
 		nodes:   []*Node{n0, n1},
 		model:   s,
 		section: sec,
+		FixityA: FixityFixed,
+		FixityB: FixityFixed,
 	}
 	s.ContinuousMembers.Append(c)
 
 	return c
 }
 
+// Support returns the support at the node or nil if the node is not supported
+func (n *Node) Support() *Support {
+	return n.support
+}
+
 type Support struct {
 	DirectionCode string  `json:"direction_code"`
 	Tx            float64 `json:"tx"`
@@ -588,7 +801,7 @@ type StringIntList []int
 
 func (s StringIntList) MarshalJSON() ([]byte, error) {
 	if len(s) == 0 {
-		return []byte(""), nil
+		return json.Marshal("")
 	}
 	builder := &strings.Builder{}
 
@@ -601,6 +814,32 @@ func (s StringIntList) MarshalJSON() ([]byte, error) {
 	return json.Marshal(builder.String())
 }
 
+func (s *StringIntList) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		// plain arrays of ids are accepted as well
+		var ids []int
+		if err := json.Unmarshal(b, &ids); err != nil {
+			return fmt.Errorf("expected a comma separated list of ids: %s", b)
+		}
+		*s = ids
+		return nil
+	}
+
+	*s = nil
+	if strings.TrimSpace(str) == "" {
+		return nil
+	}
+	for _, f := range strings.Split(str, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return fmt.Errorf("bad id %q in list %q", f, str)
+		}
+		*s = append(*s, i)
+	}
+	return nil
+}
+
 type AreaLoad struct {
 	Type             string        `json:"type"`
 	Nodes            StringIntList `json:"nodes"`
@@ -663,6 +902,30 @@ func (s *Suppress) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+func (s *Suppress) UnmarshalJSON(b []byte) error {
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	s.Suppressions = make(map[string]Suppression)
+	s.CurrentCase = ""
+	for k, v := range m {
+		if k == "current_case" {
+			if err := json.Unmarshal(v, &s.CurrentCase); err != nil {
+				return err
+			}
+			continue
+		}
+		sup := emptySuppression()
+		if err := json.Unmarshal(v, &sup); err != nil {
+			return err
+		}
+		s.Suppressions[k] = sup
+	}
+	return nil
+}
+
 type Skyciv struct {
 	DataVersion int           `json:"dataVersion"`
 	Settings    Settings      `json:"settings"`
@@ -677,10 +940,10 @@ type Skyciv struct {
 	Supports             map[int]*Support     `json:"supports"`
 	Settlements          map[int]interface{}  `json:"settlements"`
 	Groups               []*Group             `json:"groups"`
-	PointLoads           map[int]interface{}  `json:"point_loads"`
-	Moments              map[int]interface{}  `json:"moments"`
-	DistributedLoads     map[int]interface{}  `json:"distributed_loads"`
-	Pressures            map[int]interface{}  `json:"pressures"`
+	PointLoads           map[int]*PointLoad   `json:"point_loads"`
+	Moments              map[int]*Moment      `json:"moments"`
+	DistributedLoads     DistributedLoadList  `json:"distributed_loads"`
+	Pressures            map[int]*Pressure    `json:"pressures"`
 	AreaLoads            map[int]*AreaLoad    `json:"area_loads"`
 	MemberPrestressLoads map[int]interface{}  `json:"member_prestress_loads"`
 	SelfWeight           map[int]*SelfWeight  `json:"self_weight"`
@@ -691,6 +954,9 @@ type Skyciv struct {
 	SpectralLoads            map[int]interface{} `json:"spectral_loads"`
 	NotionalLoads            map[int]interface{} `json:"notional_loads"`
 	Suppress                 Suppress            `json:"suppress"`
+
+	nodeIndex *nodeIndex
+	ids       idAllocator
 }
 
 const (
@@ -701,19 +967,19 @@ const (
 
 func NewModel(mats *MaterialFile) *Skyciv {
 	var mset MaterialSet
-	var units interface{}
+	system := units.Imperial
 	if mats != nil {
 		mset = mats.materialSet()
-		units = mats.Units
+		system = mats.Units
 	} else {
 		mset = make(MaterialSet)
-		units = "imperial"
 	}
 
 	return &Skyciv{
 		DataVersion: DataVersion,
 		Settings: Settings{
-			Units: units,
+			Units:             &system,
+			MemberOffsetsAxis: MemberOffsetsGlobal,
 		},
 		Details: []Details{},
 		Nodes:   make(map[int]*Node),
@@ -727,10 +993,10 @@ func NewModel(mats *MaterialFile) *Skyciv {
 		AreaLoads:            make(map[int]*AreaLoad),
 		SelfWeight:           make(map[int]*SelfWeight),
 		Groups:               []*Group{nil, nil},
-		PointLoads:           make(map[int]interface{}),
-		Moments:              make(map[int]interface{}),
-		DistributedLoads:     make(map[int]interface{}),
-		Pressures:            make(map[int]interface{}),
+		PointLoads:           make(map[int]*PointLoad),
+		Moments:              make(map[int]*Moment),
+		DistributedLoads:     make(DistributedLoadList),
+		Pressures:            make(map[int]*Pressure),
 		MemberPrestressLoads: make(map[int]interface{}),
 		// LoadCombinations:         make(map[int]interface{}),
 		// LoadCases:                make(map[int]interface{}),
@@ -764,19 +1030,39 @@ func (m *Skyciv) NewAreaLoad(nodes ...*Node) (*AreaLoad, error) {
 	nl := NodeList(nodes).NodeIds()
 	al := &AreaLoad{
 		Nodes:            nl,
-		ColumnDirection:  nl[0:2],
+		ColumnDirection:  append(StringIntList(nil), nl[0:2]...),
 		LoadedMemberAxis: "all",
 		Type:             "one_way",
-		Id:               len(m.AreaLoads) + 1,
+		Id:               m.nextAreaLoadId(),
 	}
 	m.AreaLoads[al.Id] = al
 
 	return al, nil
 }
 
+// Tributary lumps the area of a polygon onto its corners.  The polygon is
+// fanned from its centroid and each corner takes half of the two triangles
+// touching it, which splits rectangles into equal quarters.
+func Tributary(pts []Vector) []float64 {
+	var c Vector
+	for _, p := range pts {
+		c = c.Sum(p)
+	}
+	c = c.Scale(1 / float64(len(pts)))
+
+	share := make([]float64, len(pts))
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		a := pts[i].Diff(c).Cross(pts[j].Diff(c)).Length() / 2
+		share[i] += a / 2
+		share[j] += a / 2
+	}
+	return share
+}
+
 func (m *Skyciv) NewSelfWeight() *SelfWeight {
 	sw := &SelfWeight{
-		Id: len(m.SelfWeight) + 1,
+		Id: m.nextSelfWeightId(),
 	}
 	switch m.Settings.VerticalAxis {
 	case "X":
@@ -793,9 +1079,13 @@ func (m *Skyciv) NewSelfWeight() *SelfWeight {
 	return sw
 }
 
+// MaterialFile is a materials.json file of the materials frames can be built
+// from
 type MaterialFile struct {
-	Units     interface{} `json:"units"`
-	Materials []Material  `json:"materials"`
+	Units     units.System `json:"units"`
+	Materials []Material   `json:"materials"`
+	// Grades are the design values of the grades of each material
+	Grades []DesignValues `json:"design_values,omitempty"`
 }
 
 func (f *MaterialFile) materialSet() MaterialSet {
@@ -803,22 +1093,110 @@ func (f *MaterialFile) materialSet() MaterialSet {
 	for i, m := range f.Materials {
 		pm := new(Material)
 		*pm = m
-		pm.Id = i
+		pm.Id = i + 1
 		ret[m.Name] = pm
 	}
 	return ret
 }
 
+// ReadSkyciv reads a model saved from skyciv, or exported by goframes, and
+// links its nodes, members, sections and loads back together
+func ReadSkyciv(r io.Reader) (*Skyciv, error) {
+	m := NewModel(nil)
+	m.Materials = nil
+	m.Suppress = Suppress{}
+
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	if m.Materials == nil {
+		m.Materials = make(MaterialSet)
+	}
+	if err := m.link(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// link sets the ids and back references that are not part of the json
+func (m *Skyciv) link() error {
+	for id, n := range m.Nodes {
+		n.Id = id
+		n.model = m
+	}
+	for id, sec := range m.Sections {
+		sec.Id = id
+		sec.model = m
+	}
+	for id, sup := range m.Supports {
+		sup.Id = id
+		sup.model = m
+		n, ok := m.Nodes[sup.Node]
+		if !ok {
+			return fmt.Errorf("support %d references missing node %d", id, sup.Node)
+		}
+		n.support = sup
+	}
+	for id, al := range m.AreaLoads {
+		al.Id = id
+		for _, n := range al.Nodes {
+			if _, ok := m.Nodes[n]; !ok {
+				return fmt.Errorf("area load %d references missing node %d", id, n)
+			}
+		}
+	}
+	for id, sw := range m.SelfWeight {
+		sw.Id = id
+	}
+	if err := m.ContinuousMembers.link(m); err != nil {
+		return err
+	}
+	if err := m.linkPlates(); err != nil {
+		return err
+	}
+
+	segments := make(map[int]Segment)
+	for _, s := range m.ContinuousMembers.Segments() {
+		segments[s.Id] = s
+	}
+	for id, pl := range m.PointLoads {
+		pl.Id = id
+		if err := pl.link(m, segments); err != nil {
+			return err
+		}
+	}
+	for id, mo := range m.Moments {
+		mo.Id = id
+		if err := mo.link(m); err != nil {
+			return err
+		}
+	}
+	for _, dl := range m.DistributedLoads {
+		if err := dl.link(segments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadMaterials reads a materials.json file and converts its materials to the
+// imperial units frames are built in.  Files without units are imperial.
 func ReadMaterials(r io.Reader) (f *MaterialFile, err error) {
 	f = new(MaterialFile)
 	dec := json.NewDecoder(r)
-	err = dec.Decode(f)
+	if err = dec.Decode(f); err != nil {
+		return
+	}
+	if f.Units == (units.System{}) {
+		f.Units = units.Imperial
+	}
+	err = f.ConvertUnits(units.Imperial)
 	return
 }
 
 func (m *Skyciv) NewMaterial(name string) *Material {
 	mat := &Material{
-		Id:   len(m.Materials) + 1,
+		Id:   m.nextMaterialId(),
 		Name: name,
 	}
 	m.Materials[name] = mat
@@ -826,8 +1204,7 @@ func (m *Skyciv) NewMaterial(name string) *Material {
 }
 
 func (n *Node) Colocated(x, y, z float64) bool {
-	// TODO: precision of 3 assumed
-	const eps2 = 0.001 * 0.001
+	const eps2 = colocatedEps * colocatedEps
 
 	isClose := func(a, b float64) bool {
 		d := b - a
@@ -837,16 +1214,8 @@ func (n *Node) Colocated(x, y, z float64) bool {
 	return isClose(n.X, x) && isClose(n.Y, y) && isClose(n.Z, z)
 }
 
-func (m *Skyciv) FindNearestNode(x, y, z float64) (minNode *Node) {
-	minDistance := math.MaxFloat64
-	for _, node := range m.Nodes {
-		dist := node.ToVector().Diff(Vector{x, y, z}).Length()
-		if dist < minDistance {
-			minDistance = dist
-			minNode = node
-		}
-	}
-	return
+func (m *Skyciv) FindNearestNode(x, y, z float64) *Node {
+	return m.index().nearest(x, y, z)
 }
 
 func (m *Skyciv) NewNodeInterpolate(a, b *Node, t float64) *Node {
@@ -854,31 +1223,15 @@ func (m *Skyciv) NewNodeInterpolate(a, b *Node, t float64) *Node {
 	y := (b.Y-a.Y)*t + a.Y
 	z := (b.Z-a.Z)*t + a.Z
 
-	for _, n := range m.Nodes {
-		if n.Colocated(x, y, z) {
-			return n
-		}
-	}
-
-	n := &Node{
-		X: x, Y: y, Z: z,
-		model: m,
-		Id:    len(m.Nodes) + 1,
+	if n := m.index().colocated(x, y, z); n != nil {
+		return n
 	}
-	m.Nodes[n.Id] = n
-	return n
+	return m.addNode(&Node{X: x, Y: y, Z: z})
 }
 
 func (m *Skyciv) NewNode(x, y, z float64) *Node {
-	var found *Node
-	for _, n := range m.Nodes {
-		if n.Colocated(x, y, z) {
-			return n
-		}
+	if n := m.index().colocated(x, y, z); n != nil {
+		return n
 	}
-
-	found = &Node{X: x, Y: y, Z: z, Id: len(m.Nodes) + 1, model: m}
-	m.Nodes[found.Id] = found
-	return found
-
+	return m.addNode(&Node{X: x, Y: y, Z: z})
 }
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 )
 
@@ -51,3 +53,149 @@ func TestMemberParent(t *testing.T) {
 // 		T.Errorf("children are incorrect")
 // 	}
 // }
+
+func TestReadSkyciv(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "American", "NDS", "Sawn Lumber", "8 x 10")
+	post := m.NewContinuousMember(sec, 0, 0, 0, 0, 10, 0)
+	beam := m.NewContinuousMemberBetweenNodes(sec, post.End(), m.NewNode(10, 10, 0))
+	beam.RotationAngle = 90
+	post.Begin().FixedSupport()
+	if _, err := post.Brace(beam, sec, 2, QuadrantNP); err != nil {
+		T.Fatal(err)
+	}
+	if al, err := m.NewAreaLoad(post.Begin(), post.End(), beam.End()); err != nil {
+		T.Fatal(err)
+	} else {
+		al.LoadGroup = "snow"
+		al.Direction = "Y"
+		al.Mag = -0.06
+	}
+	if _, err := m.NewMemberPointLoad(beam, 3, "Y", -1, "live"); err != nil {
+		T.Fatal(err)
+	}
+	if _, err := beam.PointLoad(5, "Y", -2, "live"); err != nil {
+		T.Fatal(err)
+	}
+	if _, err := m.NewMoment(beam.End(), "Z", 1, "live"); err != nil {
+		T.Fatal(err)
+	}
+	// the post is split by the brace so this load spans two segments
+	if _, err := m.NewDistributedLoad(post, 2, 10, "X", 0.1, 0.2, "wind"); err != nil {
+		T.Fatal(err)
+	}
+	if p, err := m.NewPlate(mat, 0.75, post.Begin(), beam.End(), m.NewNode(10, 0, 0)); err != nil {
+		T.Fatal(err)
+	} else {
+		m.NewNormalPressure(p, -0.02, "wind")
+	}
+	m.NewSelfWeight()
+	m.LoadCombinations.Mapping.DeadCases("dead", "SW1").LiveCases("live").SnowCases("snow").WindCases("wind")
+	m.LoadCombinations.Cases = []Case{
+		{Name: "1.4D", Dead: 1.4},
+		{Name: "1.2D + 1.6S", Dead: 1.2, Snow: 1.6},
+		{Name: "1.2D + W + L", Dead: 1.2, Wind: 1, Live: 1},
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+
+	r, err := ReadSkyciv(bytes.NewReader(out))
+	if err != nil {
+		T.Fatal(err)
+	}
+
+	if len(r.ContinuousMembers.Members()) != 3 {
+		T.Errorf("read %d continuous members instead of 3", len(r.ContinuousMembers.Members()))
+	}
+	if len(r.ContinuousMembers.Members()[0].Nodes()) != 3 {
+		T.Errorf("post was not rejoined from its segments")
+	}
+	if n := r.Nodes[post.Begin().Id]; n.Support() == nil {
+		T.Errorf("support not linked to its node")
+	}
+	if len(r.LoadCombinations.Mapping.Snow) != 1 || r.LoadCombinations.Cases[1].Snow != 1.6 {
+		T.Errorf("combinations not read %+v", r.LoadCombinations)
+	}
+	if r.Settings.MemberOffsetsAxis != MemberOffsetsGlobal {
+		T.Errorf("member offsets are along the %q axes", r.Settings.MemberOffsetsAxis)
+	}
+	if len(r.DistributedLoads) != 2 {
+		T.Errorf("distributed load over two segments read as %d loads", len(r.DistributedLoads))
+	}
+	for _, pl := range r.PointLoads {
+		if pl.Member != nil && (pl.Member != r.ContinuousMembers.Members()[1] || pl.Distance != 3) {
+			T.Errorf("member point load not linked back to the beam")
+		}
+	}
+
+	again, err := json.Marshal(r)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if !bytes.Equal(out, again) {
+		T.Errorf("model changed after a round trip\n%s\n%s", out, again)
+	}
+}
+
+func TestPlates(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("plywood")
+
+	a, b, c := m.NewNode(0, 0, 0), m.NewNode(4, 0, 0), m.NewNode(4, 0, 8)
+	if _, err := m.NewPlate(mat, 0.5, a, b, c, m.NewNode(0, 1, 8)); err == nil {
+		T.Errorf("plate with a node off its plane was created")
+	}
+	// an edge may pass through nodes, even the first one
+	d, e, f := m.NewNode(0, 5, 0), m.NewNode(2, 5, 0), m.NewNode(4, 5, 0)
+	if _, err := m.NewPlate(mat, 0.5, d, e, f, m.NewNode(4, 5, 8), m.NewNode(0, 5, 8)); err != nil {
+		T.Errorf("plate with a node along its first edge: %v", err)
+	}
+	if _, err := m.NewPlate(mat, 0.5, d, e, f); err == nil {
+		T.Errorf("plate of nodes in a line was created")
+	}
+
+	p, err := m.NewPlate(mat, 0.5, a, b, c, m.NewNode(0, 0, 8))
+	if err != nil {
+		T.Fatal(err)
+	}
+	if _, err := m.NewPressure(p, "Y", -0.02, "dead"); err != nil {
+		T.Fatal(err)
+	}
+
+	nodes := len(m.Nodes)
+	if err := m.MeshPlate(p, 2); err != nil {
+		T.Fatal(err)
+	}
+	if len(m.MeshedPlates) != 4 {
+		T.Errorf("plate meshed into %d instead of 4", len(m.MeshedPlates))
+	}
+	if added := len(m.Nodes) - nodes; added != 5 {
+		T.Errorf("meshing added %d nodes instead of 5", added)
+	}
+}
+
+func TestReplaceSupport(T *testing.T) {
+	m := NewModel(nil)
+	n := m.NewNode(0, 0, 0)
+	n.SpringSupport(100, 200, 0, 0, 0, 0)
+	if _, err := n.DirectionalSupport(RestraintFixed, "BPBBBB"); err != nil {
+		T.Fatal(err)
+	}
+
+	s := n.PinnedSupport()
+	if len(m.Supports) != 1 {
+		T.Errorf("replacing the support made %d supports", len(m.Supports))
+	}
+	if s.RestraintCode != RestraintPinned || s.DirectionCode != DirectionBoth {
+		T.Errorf("replaced support is %s %s", s.RestraintCode, s.DirectionCode)
+	}
+	for i := range RestraintFixed {
+		if k := s.Spring(i); k != 0 {
+			T.Errorf("replaced support kept a spring of %g in %d", k, i)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	PlateTypeAuto     = "auto"
+	PlateStateStress  = "stress"
+	PressureAxesLocal = "local"
+)
+
+// Plate is a shell element such as roof sheathing or a wall panel spanning
+// between nodes.  Thickness is in section length units and Offset moves the
+// plate along its normal.
+type Plate struct {
+	Nodes      StringIntList `json:"nodes"`
+	Thickness  float64       `json:"thickness"`
+	MaterialId int           `json:"material_id"`
+	RotZ       float64       `json:"rotZ"`
+	Type       string        `json:"type"`
+	Offset     float64       `json:"offset"`
+	State      string        `json:"state"`
+	IsMeshed   bool          `json:"is_meshed"`
+	Id         int           `json:"-"`
+}
+
+// MeshedPlate is one quadrilateral of a plate divided by MeshPlate
+type MeshedPlate struct {
+	NodeA  int     `json:"node_A"`
+	NodeB  int     `json:"node_B"`
+	NodeC  int     `json:"node_C"`
+	NodeD  int     `json:"node_D"`
+	Parent int     `json:"parent"`
+	RotZ   float64 `json:"rotZ"`
+	Id     int     `json:"-"`
+}
+
+// Pressure is a load per area on a plate.  In global axes the magnitudes act
+// along X, Y and Z, in local axes ZMag acts along the plate normal.
+type Pressure struct {
+	PlateId   int     `json:"plate_id"`
+	Axes      string  `json:"axes"`
+	XMag      float64 `json:"x_mag"`
+	YMag      float64 `json:"y_mag"`
+	ZMag      float64 `json:"z_mag"`
+	LoadGroup string  `json:"load_group"`
+	Id        int     `json:"-"`
+}
+
+// coplanar reports whether every point lies on the plane of the first two and
+// the first point off the line through them, so the edges of a plate may
+// pass through nodes in line with its corners
+func coplanar(pts []Vector) bool {
+	if len(pts) < 3 {
+		return false
+	}
+	d := pts[1].Diff(pts[0])
+	var n Vector
+	scale := 0.
+	for _, p := range pts[2:] {
+		// TODO: precision of 3 assumed like Colocated
+		if c := d.Cross(p.Diff(pts[0])); c.Length()/d.Length() > 0.001 {
+			n, scale = c, c.Length()
+			break
+		}
+	}
+	if scale == 0 {
+		return false
+	}
+	for _, p := range pts[2:] {
+		if math.Abs(n.Dot(p.Diff(pts[0])))/scale > 0.001 {
+			return false
+		}
+	}
+	return true
+}
+
+func (nl NodeList) vectors() (ret []Vector) {
+	for _, n := range nl {
+		ret = append(ret, n.ToVector())
+	}
+	return
+}
+
+// NewPlate creates a plate of the material spanning the nodes, which must all
+// lie in one plane
+func (m *Skyciv) NewPlate(material *Material, thickness float64, nodes ...*Node) (*Plate, error) {
+	if len(nodes) < 3 {
+		return nil, fmt.Errorf("plates must have at least 3 nodes")
+	}
+	if !coplanar(NodeList(nodes).vectors()) {
+		return nil, fmt.Errorf("plate nodes are not coplanar")
+	}
+
+	p := &Plate{
+		Nodes:      NodeList(nodes).NodeIds(),
+		Thickness:  thickness,
+		MaterialId: material.Id,
+		Type:       PlateTypeAuto,
+		State:      PlateStateStress,
+		Id:         m.nextPlateId(),
+	}
+	m.Plates[p.Id] = p
+	return p, nil
+}
+
+// NewPressure applies a pressure of mag along the global axis dir to a plate
+func (m *Skyciv) NewPressure(p *Plate, dir string, mag float64, loadGroup string) (*Pressure, error) {
+	v, err := directionVector(dir)
+	if err != nil {
+		return nil, err
+	}
+	v = v.Scale(mag)
+	pr := &Pressure{
+		PlateId:   p.Id,
+		Axes:      "global",
+		XMag:      v.X,
+		YMag:      v.Y,
+		ZMag:      v.Z,
+		LoadGroup: loadGroup,
+		Id:        m.nextPressureId(),
+	}
+	m.Pressures[pr.Id] = pr
+	return pr, nil
+}
+
+// NewNormalPressure applies a pressure of mag along the normal of a plate,
+// such as wind suction on sheathing
+func (m *Skyciv) NewNormalPressure(p *Plate, mag float64, loadGroup string) *Pressure {
+	pr := &Pressure{
+		PlateId:   p.Id,
+		Axes:      PressureAxesLocal,
+		ZMag:      mag,
+		LoadGroup: loadGroup,
+		Id:        m.nextPressureId(),
+	}
+	m.Pressures[pr.Id] = pr
+	return pr
+}
+
+// MeshPlate divides a four node plate into divisions by divisions meshed
+// plates, adding the interior nodes to the model
+func (m *Skyciv) MeshPlate(p *Plate, divisions int) error {
+	if len(p.Nodes) != 4 {
+		return fmt.Errorf("only four node plates can be meshed")
+	}
+	if divisions < 1 {
+		return fmt.Errorf("plates need at least one division")
+	}
+
+	var corners [4]*Node
+	for i, id := range p.Nodes {
+		corners[i] = m.Nodes[id]
+	}
+
+	// bilinear interpolation between the corners
+	grid := make([][]*Node, divisions+1)
+	for i := range grid {
+		u := float64(i) / float64(divisions)
+		a := m.NewNodeInterpolate(corners[0], corners[1], u)
+		b := m.NewNodeInterpolate(corners[3], corners[2], u)
+		grid[i] = make([]*Node, divisions+1)
+		for j := range grid[i] {
+			grid[i][j] = m.NewNodeInterpolate(a, b, float64(j)/float64(divisions))
+		}
+	}
+
+	for i := 0; i < divisions; i++ {
+		for j := 0; j < divisions; j++ {
+			mp := &MeshedPlate{
+				NodeA:  grid[i][j].Id,
+				NodeB:  grid[i+1][j].Id,
+				NodeC:  grid[i+1][j+1].Id,
+				NodeD:  grid[i][j+1].Id,
+				Parent: p.Id,
+				RotZ:   p.RotZ,
+				Id:     m.nextMeshedPlateId(),
+			}
+			m.MeshedPlates[mp.Id] = mp
+		}
+	}
+	p.IsMeshed = true
+	return nil
+}
+
+// linkPlates sets the ids of plates and their loads read from json and checks
+// their references
+func (m *Skyciv) linkPlates() error {
+	for id, p := range m.Plates {
+		p.Id = id
+		for _, n := range p.Nodes {
+			if _, ok := m.Nodes[n]; !ok {
+				return fmt.Errorf("plate %d references missing node %d", id, n)
+			}
+		}
+	}
+	for id, mp := range m.MeshedPlates {
+		mp.Id = id
+		if _, ok := m.Plates[mp.Parent]; !ok {
+			return fmt.Errorf("meshed plate %d references missing plate %d", id, mp.Parent)
+		}
+	}
+	for id, pr := range m.Pressures {
+		pr.Id = id
+		if _, ok := m.Plates[pr.PlateId]; !ok {
+			return fmt.Errorf("pressure %d references missing plate %d", id, pr.PlateId)
+		}
+	}
+	return nil
+}
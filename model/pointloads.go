@@ -0,0 +1,398 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// directionVector turns a global axis name into a unit vector
+func directionVector(dir string) (v Vector, err error) {
+	switch dir {
+	case "X":
+		v.X = 1
+	case "Y":
+		v.Y = 1
+	case "Z":
+		v.Z = 1
+	default:
+		err = fmt.Errorf("unknown direction %q, expected X, Y or Z", dir)
+	}
+	return
+}
+
+// segmentAt finds the segment of mem containing the point distance from its
+// Begin, and the position of the point along the segment as a percent
+func (m *Skyciv) segmentAt(mem *ContinuousMember, distance float64) (Segment, float64, error) {
+	var last Segment
+	found := false
+	start := 0.
+	for _, s := range m.ContinuousMembers.Segments() {
+		if s.Member != mem {
+			continue
+		}
+		l := Distance(s.A, s.B)
+		if distance <= start+l {
+			return s, 100 * (distance - start) / l, nil
+		}
+		start += l
+		last, found = s, true
+	}
+	// allow for rounding at the very end of the member
+	if found && distance-start < 1e-9 {
+		return last, 100, nil
+	}
+	return Segment{}, 0, fmt.Errorf("distance %f is not along the member", distance)
+}
+
+// distanceAlong finds the distance from Begin of the continuous member to a
+// position given as a percent along a segment
+func (s Segment) distanceAlong(position float64) float64 {
+	d := 0.
+	for i := 0; i < s.Index; i++ {
+		d += Distance(s.Member.nodes[i], s.Member.nodes[i+1])
+	}
+	return d + position/100*Distance(s.A, s.B)
+}
+
+// pointJSON is how skyciv stores point loads and moments
+type pointJSON struct {
+	Type      string   `json:"type"`
+	Node      *int     `json:"node"`
+	Member    *int     `json:"member"`
+	Position  *float64 `json:"position"`
+	XMag      float64  `json:"x_mag"`
+	YMag      float64  `json:"y_mag"`
+	ZMag      float64  `json:"z_mag"`
+	LoadGroup string   `json:"load_group"`
+}
+
+// PointLoad is a force on a node, or on a continuous member Distance from its
+// Begin.  Mag is in global axes.
+type PointLoad struct {
+	Node      *Node
+	Member    *ContinuousMember
+	Distance  float64
+	Mag       Vector
+	LoadGroup string
+	Id        int
+
+	model *Skyciv
+	raw   *pointJSON
+}
+
+// NewPointLoad puts a force of mag along the global axis dir on a node
+func (m *Skyciv) NewPointLoad(n *Node, dir string, mag float64, loadGroup string) (*PointLoad, error) {
+	v, err := directionVector(dir)
+	if err != nil {
+		return nil, err
+	}
+	pl := &PointLoad{
+		Node:      n,
+		Mag:       v.Scale(mag),
+		LoadGroup: loadGroup,
+		Id:        m.nextPointLoadId(),
+		model:     m,
+	}
+	m.PointLoads[pl.Id] = pl
+	return pl, nil
+}
+
+// NewMemberPointLoad puts a force of mag along the global axis dir on mem,
+// distance from its Begin, without splitting the member
+func (m *Skyciv) NewMemberPointLoad(mem *ContinuousMember, distance float64, dir string, mag float64, loadGroup string) (*PointLoad, error) {
+	v, err := directionVector(dir)
+	if err != nil {
+		return nil, err
+	}
+	if distance < 0 || distance > mem.Length() {
+		return nil, fmt.Errorf("distance %f is not along the member", distance)
+	}
+	pl := &PointLoad{
+		Member:    mem,
+		Distance:  distance,
+		Mag:       v.Scale(mag),
+		LoadGroup: loadGroup,
+		Id:        m.nextPointLoadId(),
+		model:     m,
+	}
+	m.PointLoads[pl.Id] = pl
+	return pl, nil
+}
+
+// PointLoad splits the member distance from its Begin and hangs a force of mag
+// along the global axis dir from the new node
+func (mem *ContinuousMember) PointLoad(distance float64, dir string, mag float64, loadGroup string) (*PointLoad, error) {
+	n, err := mem.Split(distance)
+	if err != nil {
+		return nil, err
+	}
+	return mem.model.NewPointLoad(n, dir, mag, loadGroup)
+}
+
+func (pl *PointLoad) MarshalJSON() ([]byte, error) {
+	out := pointJSON{
+		XMag:      pl.Mag.X,
+		YMag:      pl.Mag.Y,
+		ZMag:      pl.Mag.Z,
+		LoadGroup: pl.LoadGroup,
+	}
+	if pl.Node != nil {
+		out.Type = "n"
+		out.Node = &pl.Node.Id
+		return json.Marshal(out)
+	}
+
+	seg, pos, err := pl.model.segmentAt(pl.Member, pl.Distance)
+	if err != nil {
+		return nil, fmt.Errorf("point load %d: %v", pl.Id, err)
+	}
+	out.Type = "m"
+	out.Member = &seg.Id
+	out.Position = &pos
+	return json.Marshal(out)
+}
+
+func (pl *PointLoad) UnmarshalJSON(b []byte) error {
+	pl.raw = new(pointJSON)
+	if err := json.Unmarshal(b, pl.raw); err != nil {
+		return err
+	}
+	pl.Mag = Vector{pl.raw.XMag, pl.raw.YMag, pl.raw.ZMag}
+	pl.LoadGroup = pl.raw.LoadGroup
+	return nil
+}
+
+// link resolves the node or member read by UnmarshalJSON
+func (pl *PointLoad) link(m *Skyciv, segments map[int]Segment) error {
+	pl.model = m
+	raw := pl.raw
+	if raw == nil {
+		return nil
+	}
+	pl.raw = nil
+
+	switch raw.Type {
+	case "n":
+		if raw.Node == nil || m.Nodes[*raw.Node] == nil {
+			return fmt.Errorf("point load %d references a missing node", pl.Id)
+		}
+		pl.Node = m.Nodes[*raw.Node]
+	case "m":
+		if raw.Member == nil || raw.Position == nil {
+			return fmt.Errorf("point load %d has no member or position", pl.Id)
+		}
+		s, ok := segments[*raw.Member]
+		if !ok {
+			return fmt.Errorf("point load %d references missing member %d", pl.Id, *raw.Member)
+		}
+		pl.Member = s.Member
+		pl.Distance = s.distanceAlong(*raw.Position)
+	default:
+		return fmt.Errorf("point load %d has unknown type %q", pl.Id, raw.Type)
+	}
+	return nil
+}
+
+// Moment is a moment about the global axes applied to a node.  Use
+// ContinuousMember.Split to apply one along a member.
+type Moment struct {
+	Node      *Node
+	Mag       Vector
+	LoadGroup string
+	Id        int
+
+	raw *pointJSON
+}
+
+// NewMoment applies a moment of mag about the global axis dir to a node
+func (m *Skyciv) NewMoment(n *Node, dir string, mag float64, loadGroup string) (*Moment, error) {
+	v, err := directionVector(dir)
+	if err != nil {
+		return nil, err
+	}
+	mo := &Moment{
+		Node:      n,
+		Mag:       v.Scale(mag),
+		LoadGroup: loadGroup,
+		Id:        m.nextMomentId(),
+	}
+	m.Moments[mo.Id] = mo
+	return mo, nil
+}
+
+func (mo *Moment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pointJSON{
+		Type:      "n",
+		Node:      &mo.Node.Id,
+		XMag:      mo.Mag.X,
+		YMag:      mo.Mag.Y,
+		ZMag:      mo.Mag.Z,
+		LoadGroup: mo.LoadGroup,
+	})
+}
+
+func (mo *Moment) UnmarshalJSON(b []byte) error {
+	mo.raw = new(pointJSON)
+	if err := json.Unmarshal(b, mo.raw); err != nil {
+		return err
+	}
+	mo.Mag = Vector{mo.raw.XMag, mo.raw.YMag, mo.raw.ZMag}
+	mo.LoadGroup = mo.raw.LoadGroup
+	return nil
+}
+
+func (mo *Moment) link(m *Skyciv) error {
+	raw := mo.raw
+	if raw == nil {
+		return nil
+	}
+	mo.raw = nil
+
+	if raw.Type != "n" {
+		return fmt.Errorf("moment %d: only moments on nodes are supported", mo.Id)
+	}
+	if raw.Node == nil || m.Nodes[*raw.Node] == nil {
+		return fmt.Errorf("moment %d references a missing node", mo.Id)
+	}
+	mo.Node = m.Nodes[*raw.Node]
+	return nil
+}
+
+// DistributedLoad varies linearly from MagA at Start to MagB at End, both
+// distances from the Begin of the member.  Magnitudes are force per length
+// in global axes.
+type DistributedLoad struct {
+	Member     *ContinuousMember
+	Start, End float64
+	MagA, MagB Vector
+	LoadGroup  string
+	Id         int
+
+	raw *distributedJSON
+}
+
+// NewDistributedLoad loads mem between start and end, distances from its
+// Begin, with a load along the global axis dir that varies linearly from magA
+// to magB
+func (m *Skyciv) NewDistributedLoad(mem *ContinuousMember, start, end float64, dir string, magA, magB float64, loadGroup string) (*DistributedLoad, error) {
+	v, err := directionVector(dir)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || end > mem.Length() || start >= end {
+		return nil, fmt.Errorf("distributed load from %f to %f is not along the member", start, end)
+	}
+	dl := &DistributedLoad{
+		Member:    mem,
+		Start:     start,
+		End:       end,
+		MagA:      v.Scale(magA),
+		MagB:      v.Scale(magB),
+		LoadGroup: loadGroup,
+		Id:        m.nextDistributedLoadId(),
+	}
+	m.DistributedLoads[dl.Id] = dl
+	return dl, nil
+}
+
+// MagAt interpolates the load at a distance from the Begin of the member
+func (dl *DistributedLoad) MagAt(distance float64) Vector {
+	t := (distance - dl.Start) / (dl.End - dl.Start)
+	return dl.MagA.Scale(1 - t).Sum(dl.MagB.Scale(t))
+}
+
+type distributedJSON struct {
+	Member    int     `json:"member"`
+	XMagA     float64 `json:"x_mag_A"`
+	YMagA     float64 `json:"y_mag_A"`
+	ZMagA     float64 `json:"z_mag_A"`
+	XMagB     float64 `json:"x_mag_B"`
+	YMagB     float64 `json:"y_mag_B"`
+	ZMagB     float64 `json:"z_mag_B"`
+	PositionA float64 `json:"position_A"`
+	PositionB float64 `json:"position_B"`
+	LoadGroup string  `json:"load_group"`
+	Axes      string  `json:"axes"`
+}
+
+// DistributedLoadList exports each distributed load as one skyciv load per
+// segment of its member that it covers
+type DistributedLoadList map[int]*DistributedLoad
+
+func (l DistributedLoadList) MarshalJSON() ([]byte, error) {
+	ids := make([]int, 0, len(l))
+	for id := range l {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	out := make(map[int]distributedJSON)
+	k := 1
+	for _, id := range ids {
+		dl := l[id]
+		start := 0.
+		for i := 1; i < len(dl.Member.nodes); i++ {
+			length := Distance(dl.Member.nodes[i-1], dl.Member.nodes[i])
+			a, b := math.Max(dl.Start, start), math.Min(dl.End, start+length)
+			if a < b {
+				seg, _, err := dl.Member.model.segmentAt(dl.Member, (a+b)/2)
+				if err != nil {
+					return nil, fmt.Errorf("distributed load %d: %v", id, err)
+				}
+				magA, magB := dl.MagAt(a), dl.MagAt(b)
+				out[k] = distributedJSON{
+					Member: seg.Id,
+					XMagA:  magA.X, YMagA: magA.Y, ZMagA: magA.Z,
+					XMagB: magB.X, YMagB: magB.Y, ZMagB: magB.Z,
+					PositionA: 100 * (a - start) / length,
+					PositionB: 100 * (b - start) / length,
+					LoadGroup: dl.LoadGroup,
+					Axes:      "global",
+				}
+				k++
+			}
+			start += length
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (l *DistributedLoadList) UnmarshalJSON(b []byte) error {
+	in := make(map[int]*distributedJSON)
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*l = make(DistributedLoadList)
+	for id, raw := range in {
+		if raw.Axes != "" && raw.Axes != "global" {
+			return fmt.Errorf("distributed load %d: only global axes are supported", id)
+		}
+		(*l)[id] = &DistributedLoad{
+			Id:        id,
+			MagA:      Vector{raw.XMagA, raw.YMagA, raw.ZMagA},
+			MagB:      Vector{raw.XMagB, raw.YMagB, raw.ZMagB},
+			LoadGroup: raw.LoadGroup,
+			raw:       raw,
+		}
+	}
+	return nil
+}
+
+func (dl *DistributedLoad) link(segments map[int]Segment) error {
+	raw := dl.raw
+	if raw == nil {
+		return nil
+	}
+	dl.raw = nil
+
+	s, ok := segments[raw.Member]
+	if !ok {
+		return fmt.Errorf("distributed load %d references missing member %d", dl.Id, raw.Member)
+	}
+	dl.Member = s.Member
+	dl.Start = s.distanceAlong(raw.PositionA)
+	dl.End = s.distanceAlong(raw.PositionB)
+	return nil
+}
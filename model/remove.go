@@ -0,0 +1,166 @@
+package model
+
+import (
+	"fmt"
+)
+
+// uses reports whether the member passes through n
+func (mem *ContinuousMember) uses(n *Node) bool {
+	for _, p := range mem.nodes {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveNode removes n along with its support and any loads applied to it.  A
+// node still used by a member or plate cannot be removed, remove those first.
+func (m *Skyciv) RemoveNode(n *Node) error {
+	if m.Nodes[n.Id] != n {
+		return fmt.Errorf("node %d is not in the model", n.Id)
+	}
+	if m.connected(n) {
+		return fmt.Errorf("node %d is still used by a member or plate", n.Id)
+	}
+
+	if n.support != nil {
+		delete(m.Supports, n.support.Id)
+		n.support = nil
+	}
+	for id, pl := range m.PointLoads {
+		if pl.Node == n {
+			delete(m.PointLoads, id)
+		}
+	}
+	for id, mo := range m.Moments {
+		if mo.Node == n {
+			delete(m.Moments, id)
+		}
+	}
+	// an area load cannot lose a corner and keep its shape
+	for id, al := range m.AreaLoads {
+		for _, an := range al.Nodes {
+			if an == n.Id {
+				delete(m.AreaLoads, id)
+				break
+			}
+		}
+	}
+
+	m.index().remove(n)
+	delete(m.Nodes, n.Id)
+	n.model = nil
+	return nil
+}
+
+// RemoveMember removes mem and the loads along it.  Nodes of the member left
+// without any member or plate are removed as well.
+func (m *Skyciv) RemoveMember(mem *ContinuousMember) error {
+	l := &m.ContinuousMembers
+	i := 0
+	for ; i < len(l.members); i++ {
+		if l.members[i] == mem {
+			break
+		}
+	}
+	if i == len(l.members) {
+		return fmt.Errorf("member is not in the model")
+	}
+	l.members = append(l.members[:i], l.members[i+1:]...)
+
+	for id, pl := range m.PointLoads {
+		if pl.Member == mem {
+			delete(m.PointLoads, id)
+		}
+	}
+	for id, dl := range m.DistributedLoads {
+		if dl.Member == mem {
+			delete(m.DistributedLoads, id)
+		}
+	}
+
+	for _, n := range mem.nodes {
+		if m.Nodes[n.Id] != n || m.connected(n) {
+			continue
+		}
+		if err := m.RemoveNode(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connected reports whether any member, plate or meshed plate uses n
+func (m *Skyciv) connected(n *Node) bool {
+	for _, mem := range m.ContinuousMembers.members {
+		if mem.uses(n) {
+			return true
+		}
+	}
+	for _, p := range m.Plates {
+		for _, pn := range p.Nodes {
+			if pn == n.Id {
+				return true
+			}
+		}
+	}
+	for _, mp := range m.MeshedPlates {
+		if mp.NodeA == n.Id || mp.NodeB == n.Id || mp.NodeC == n.Id || mp.NodeD == n.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSupport removes the support, leaving its node free
+func (m *Skyciv) RemoveSupport(s *Support) error {
+	if m.Supports[s.Id] != s {
+		return fmt.Errorf("support %d is not in the model", s.Id)
+	}
+	delete(m.Supports, s.Id)
+	if n, ok := m.Nodes[s.Node]; ok && n.support == s {
+		n.support = nil
+	}
+	return nil
+}
+
+// RemoveLoad removes a point load, moment, distributed load, area load,
+// pressure or self weight from the model
+func (m *Skyciv) RemoveLoad(load interface{}) error {
+	switch l := load.(type) {
+	case *PointLoad:
+		if m.PointLoads[l.Id] == l {
+			delete(m.PointLoads, l.Id)
+			return nil
+		}
+	case *Moment:
+		if m.Moments[l.Id] == l {
+			delete(m.Moments, l.Id)
+			return nil
+		}
+	case *DistributedLoad:
+		if m.DistributedLoads[l.Id] == l {
+			delete(m.DistributedLoads, l.Id)
+			return nil
+		}
+	case *AreaLoad:
+		if m.AreaLoads[l.Id] == l {
+			delete(m.AreaLoads, l.Id)
+			return nil
+		}
+	case *Pressure:
+		if m.Pressures[l.Id] == l {
+			delete(m.Pressures, l.Id)
+			return nil
+		}
+	case *SelfWeight:
+		if m.SelfWeight[l.Id] == l {
+			delete(m.SelfWeight, l.Id)
+			return nil
+		}
+	default:
+		return fmt.Errorf("%T is not a load", load)
+	}
+	return fmt.Errorf("%T is not in the model", load)
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRemove(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "test")
+
+	// a portal with a header that will be removed for a door
+	left := m.NewContinuousMember(sec, 0, 0, 0, 0, 8, 0)
+	right := m.NewContinuousMember(sec, 4, 0, 0, 4, 8, 0)
+	header := m.NewContinuousMember(sec, 0, 7, 0, 4, 7, 0)
+	top := m.NewContinuousMember(sec, 0, 8, 0, 4, 8, 0)
+	left.SplitAt(0, 7, 0)
+	right.SplitAt(4, 7, 0)
+	left.Begin().FixedSupport()
+	right.Begin().FixedSupport()
+	if _, err := m.NewDistributedLoad(header, 0, 4, "Y", -1, -1, "dead"); err != nil {
+		T.Fatal(err)
+	}
+	if _, err := m.NewDistributedLoad(top, 0, 4, "Y", -1, -1, "dead"); err != nil {
+		T.Fatal(err)
+	}
+
+	if err := m.RemoveNode(header.Begin()); err == nil {
+		T.Errorf("removed a node still used by members")
+	}
+	if err := m.RemoveMember(header); err != nil {
+		T.Fatal(err)
+	}
+	if len(m.ContinuousMembers.Members()) != 3 || len(m.DistributedLoads) != 1 {
+		T.Errorf("header or its load was not removed")
+	}
+	if len(m.Nodes) != 6 {
+		T.Errorf("nodes of the header shared with posts were removed, %d left", len(m.Nodes))
+	}
+
+	// a node added after a removal must not collide with an existing id
+	extra := m.NewNode(2, 8, 0)
+	for id, n := range m.Nodes {
+		if n.Id != id {
+			T.Errorf("node %d stored under id %d", n.Id, id)
+		}
+	}
+	if err := m.RemoveNode(extra); err != nil {
+		T.Fatal(err)
+	}
+
+	if err := m.RemoveSupport(right.Begin().Support()); err != nil {
+		T.Fatal(err)
+	}
+	if right.Begin().Support() != nil || len(m.Supports) != 1 {
+		T.Errorf("support not removed from its node")
+	}
+	if err := m.RemoveSupport(&Support{}); err == nil {
+		T.Errorf("removed a support that is not in the model")
+	}
+
+	for _, dl := range m.DistributedLoads {
+		if err := m.RemoveLoad(dl); err != nil {
+			T.Fatal(err)
+		}
+	}
+	if len(m.DistributedLoads) != 0 {
+		T.Errorf("distributed load not removed")
+	}
+	if err := m.RemoveLoad(left); err == nil {
+		T.Errorf("removed a member as a load")
+	}
+}
+
+func TestRemoveMeshedNode(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("plywood")
+	p, err := m.NewPlate(mat, 0.5, m.NewNode(0, 0, 0), m.NewNode(4, 0, 0), m.NewNode(4, 0, 8), m.NewNode(0, 0, 8))
+	if err != nil {
+		T.Fatal(err)
+	}
+	if err := m.MeshPlate(p, 2); err != nil {
+		T.Fatal(err)
+	}
+
+	// the middle of the mesh is used only by the meshed plates
+	middle := m.MeshedPlates[1].NodeC
+	for _, mp := range m.MeshedPlates {
+		if mp.NodeA != middle && mp.NodeB != middle && mp.NodeC != middle && mp.NodeD != middle {
+			T.Fatalf("node %d is not the middle of the mesh", middle)
+		}
+	}
+	if err := m.RemoveNode(m.Nodes[middle]); err == nil {
+		T.Errorf("removed a node still used by meshed plates")
+	}
+}
+
+func TestRenumber(T *testing.T) {
+	m := NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "test")
+
+	var mems []*ContinuousMember
+	for i := 0; i < 4; i++ {
+		x := float64(i)
+		mems = append(mems, m.NewContinuousMember(sec, x, 0, 0, x, 8, 0))
+		mems[i].Begin().PinnedSupport()
+	}
+	al, err := m.NewAreaLoad(mems[2].End(), mems[3].End(), mems[3].Begin(), mems[2].Begin())
+	if err != nil {
+		T.Fatal(err)
+	}
+	if err := m.RemoveMember(mems[0]); err != nil {
+		T.Fatal(err)
+	}
+	if _, ok := m.Nodes[1]; ok {
+		T.Errorf("node 1 left behind by its member")
+	}
+
+	m.Renumber()
+
+	if _, ok := m.Nodes[1]; !ok || len(m.Nodes) != 6 {
+		T.Errorf("nodes not compacted")
+	}
+	for id, sup := range m.Supports {
+		if sup.Id != id || m.Nodes[sup.Node].Support() != sup {
+			T.Errorf("support %d does not point back to its node", id)
+		}
+	}
+	for i, n := range []*Node{mems[2].End(), mems[3].End(), mems[3].Begin(), mems[2].Begin()} {
+		if al.Nodes[i] != n.Id {
+			T.Errorf("area load corner %d is node %d instead of %d", i, al.Nodes[i], n.Id)
+		}
+	}
+
+	// a renumbered model reads back the same
+	out, err := json.Marshal(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	r, err := ReadSkyciv(bytes.NewReader(out))
+	if err != nil {
+		T.Fatal(err)
+	}
+	again, err := json.Marshal(r)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if !bytes.Equal(out, again) {
+		T.Errorf("renumbered model changed after a round trip")
+	}
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	lbPerKip    = 1000.
+	sqInPerSqFt = 144.
+	inPerFt     = 12.
+)
+
+// sectionArea reads the area stored on the section, the default area of
+// SeismicWeights
+func sectionArea(sec *Section) (float64, error) {
+	if sec.Area <= 0 {
+		return 0, fmt.Errorf("section %d has no area", sec.Id)
+	}
+	return sec.Area, nil
+}
+
+// lumpOnMember splits a weight distance from the Begin of mem between the
+// nodes on either side of it
+func lumpOnMember(weights map[*Node]float64, mem *ContinuousMember, distance, w float64) {
+	start := 0.
+	for i := 1; i < len(mem.nodes); i++ {
+		l := Distance(mem.nodes[i-1], mem.nodes[i])
+		if distance <= start+l || i == len(mem.nodes)-1 {
+			t := (distance - start) / l
+			weights[mem.nodes[i-1]] += w * (1 - t)
+			weights[mem.nodes[i]] += w * t
+			return
+		}
+		start += l
+	}
+}
+
+// SeismicWeights lumps the self weight and gravity loads of the load groups,
+// usually the dead load groups, onto the nodes in kip.  Members and plates
+// weigh the density of their material times their volume, where area gives
+// the area of member sections in in^2 and defaults to the Area of the
+// section.  Loads only count along -Y.  Snow is usually not in the groups,
+// callers add the 20% of flat roof snow over 30 psf that ASCE 7 12.7.2
+// counts.
+func (m *Skyciv) SeismicWeights(area func(*Section) (float64, error), loadGroups ...string) (map[*Node]float64, error) {
+	if area == nil {
+		area = sectionArea
+	}
+	inGroups := make(map[string]bool)
+	for _, g := range loadGroups {
+		inGroups[g] = true
+	}
+	weights := make(map[*Node]float64)
+
+	gravity := 0.
+	for _, sw := range m.SelfWeight {
+		if inGroups[sw.LoadGroup] {
+			gravity -= sw.Y
+		}
+	}
+	if gravity != 0 {
+		for _, s := range m.ContinuousMembers.Segments() {
+			sec := s.Member.Section()
+			mat := m.Materials.ById(sec.MaterialId)
+			if mat == nil {
+				return nil, fmt.Errorf("section %d references missing material %d", sec.Id, sec.MaterialId)
+			}
+			a, err := area(sec)
+			if err != nil {
+				return nil, err
+			}
+			w := gravity * mat.Density / lbPerKip * a / sqInPerSqFt * Distance(s.A, s.B)
+			weights[s.A] += w / 2
+			weights[s.B] += w / 2
+		}
+
+		for _, p := range m.Plates {
+			mat := m.Materials.ById(p.MaterialId)
+			if mat == nil {
+				return nil, fmt.Errorf("plate %d references missing material %d", p.Id, p.MaterialId)
+			}
+			nodes, err := m.nodesOf(p.Nodes)
+			if err != nil {
+				return nil, fmt.Errorf("plate %d: %v", p.Id, err)
+			}
+			share := Tributary(vectors(nodes))
+			for i, n := range nodes {
+				weights[n] += gravity * mat.Density / lbPerKip * p.Thickness / inPerFt * share[i]
+			}
+		}
+	}
+
+	for _, al := range m.AreaLoads {
+		if !inGroups[al.LoadGroup] || al.Direction != "Y" {
+			continue
+		}
+		nodes, err := m.nodesOf(al.Nodes)
+		if err != nil {
+			return nil, fmt.Errorf("area load %d: %v", al.Id, err)
+		}
+		share := Tributary(vectors(nodes))
+		for i, n := range nodes {
+			weights[n] -= al.Mag * share[i]
+		}
+	}
+
+	for _, pl := range m.PointLoads {
+		if !inGroups[pl.LoadGroup] {
+			continue
+		}
+		if pl.Node != nil {
+			weights[pl.Node] -= pl.Mag.Y
+		} else {
+			lumpOnMember(weights, pl.Member, pl.Distance, -pl.Mag.Y)
+		}
+	}
+
+	// distributed loads are lumped at the middle of each piece along the
+	// member segments
+	for _, dl := range m.DistributedLoads {
+		if !inGroups[dl.LoadGroup] {
+			continue
+		}
+		start := 0.
+		for i := 1; i < len(dl.Member.nodes); i++ {
+			l := Distance(dl.Member.nodes[i-1], dl.Member.nodes[i])
+			a, b := math.Max(dl.Start, start), math.Min(dl.End, start+l)
+			if a < b {
+				w := -(dl.MagAt(a).Y + dl.MagAt(b).Y) / 2 * (b - a)
+				lumpOnMember(weights, dl.Member, (a+b)/2, w)
+			}
+			start += l
+		}
+	}
+
+	return weights, nil
+}
+
+// nodesOf finds the nodes of a list of node ids
+func (m *Skyciv) nodesOf(ids []int) ([]*Node, error) {
+	nodes := make([]*Node, len(ids))
+	for i, id := range ids {
+		n, ok := m.Nodes[id]
+		if !ok {
+			return nil, fmt.Errorf("missing node %d", id)
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
+
+func vectors(nodes []*Node) []Vector {
+	ret := make([]Vector, len(nodes))
+	for i, n := range nodes {
+		ret[i] = n.ToVector()
+	}
+	return ret
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSeismicWeights(T *testing.T) {
+	m := portal()
+	m.NewSelfWeight()
+	if _, err := m.SeismicWeights(nil, "dead", "SW1"); err == nil {
+		T.Errorf("sections without an area accepted")
+	}
+
+	// 1 kip/ft of member self weight
+	for _, mat := range m.Materials {
+		mat.Density = 1000
+	}
+	area := func(*Section) (float64, error) { return 144, nil }
+	weights, err := m.SeismicWeights(area, "dead", "SW1")
+	if err != nil {
+		T.Fatal(err)
+	}
+
+	total := 0.
+	for _, w := range weights {
+		total += w
+	}
+	if math.Abs(total-36) > 1e-9 {
+		T.Errorf("total seismic weight %f instead of 36", total)
+	}
+	// each roof corner carries half the beam and its load and half a post
+	roof := m.FindNearestNode(0, 8, 0)
+	if w := weights[roof]; math.Abs(w-14) > 1e-9 {
+		T.Errorf("roof corner weighs %f instead of 14", w)
+	}
+
+	// only the listed groups count
+	weights, _ = m.SeismicWeights(area, "SW1")
+	if w := weights[roof]; math.Abs(w-9) > 1e-9 {
+		T.Errorf("roof corner self weight %f instead of 9", w)
+	}
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+// Restraint codes for supports.  Each letter fixes (F), releases (R) or puts a
+// spring (S) on the node in global TX, TY, TZ, RX, RY and RZ.
+const (
+	RestraintFixed  = "FFFFFF"
+	RestraintPinned = "FFFRRR"
+
+	// Direction codes make a support act both ways (B) or only against
+	// positive (P) or negative (N) displacements in each degree of freedom
+	DirectionBoth = "BBBBBB"
+)
+
+// NewSupport supports the node with the given restraint code.  If the node is
+// already supported its restraint is replaced instead, acting both ways and
+// without springs.
+func (n *Node) NewSupport(restraint string) *Support {
+	if s := n.support; s != nil {
+		s.RestraintCode = restraint
+		s.DirectionCode = DirectionBoth
+		for i := range RestraintFixed {
+			*s.spring(i) = 0
+		}
+		return s
+	}
+
+	s := &Support{
+		DirectionCode: DirectionBoth,
+		Node:          n.Id,
+		RestraintCode: restraint,
+		Id:            n.model.nextSupportId(),
+		model:         n.model,
+	}
+	n.support = s
+	n.model.Supports[s.Id] = s
+	return s
+}
+
+// PinnedSupport restrains translation but leaves the node free to rotate
+func (n *Node) PinnedSupport() *Support {
+	return n.NewSupport(RestraintPinned)
+}
+
+// RollerSupport restrains only the vertical translation of the node
+func (n *Node) RollerSupport() *Support {
+	code := []byte("RRRRRR")
+	code[n.model.verticalIndex()] = 'F'
+	return n.NewSupport(string(code))
+}
+
+// DirectionalSupport restrains the node with a restraint code that acts only
+// in the directions of the direction code, for example "BPBBBB" to let a post
+// lift off its footing
+func (n *Node) DirectionalSupport(restraint, direction string) (*Support, error) {
+	if len(direction) != len(RestraintFixed) {
+		return nil, fmt.Errorf("direction code %q is not %d characters", direction, len(RestraintFixed))
+	}
+	for _, c := range direction {
+		if c != 'B' && c != 'P' && c != 'N' {
+			return nil, fmt.Errorf("unknown direction %q in %q", c, direction)
+		}
+	}
+	s := n.NewSupport(restraint)
+	s.DirectionCode = direction
+	return s, nil
+}
+
+// SpringSupport supports the node with springs of the given stiffness in each
+// degree of freedom, in force per length and moment per radian.  A stiffness
+// of zero releases the degree of freedom and math.Inf(1) fixes it.
+func (n *Node) SpringSupport(tx, ty, tz, rx, ry, rz float64) *Support {
+	s := n.NewSupport(RestraintFixed)
+	for i, k := range []float64{tx, ty, tz, rx, ry, rz} {
+		s.SetSpring(i, k)
+	}
+	return s
+}
+
+// verticalIndex is the translational degree of freedom along the vertical
+// axis
+func (m *Skyciv) verticalIndex() int {
+	switch m.Settings.VerticalAxis {
+	case "X":
+		return 0
+	case "Z":
+		return 2
+	}
+	return 1
+}
+
+// SetSpring changes a single degree of freedom, 0 through 5 for TX through
+// RZ, to a spring of stiffness k.  Zero releases it and math.Inf(1) fixes it.
+func (s *Support) SetSpring(dof int, k float64) {
+	code := []byte(s.RestraintCode)
+	switch {
+	case k == 0:
+		code[dof] = 'R'
+	case math.IsInf(k, 1):
+		code[dof], k = 'F', 0
+	default:
+		code[dof] = 'S'
+	}
+	s.RestraintCode = string(code)
+	*s.spring(dof) = k
+}
+
+// Spring returns the stiffness of the spring in a degree of freedom
+func (s *Support) Spring(dof int) float64 {
+	return *s.spring(dof)
+}
+
+func (s *Support) spring(dof int) *float64 {
+	return [...]*float64{&s.Tx, &s.Ty, &s.Tz, &s.Rx, &s.Ry, &s.Rz}[dof]
+}
+
+// SetRestraint changes a single degree of freedom of the support to fixed
+// (F) or released (R)
+func (s *Support) SetRestraint(dof int, restraint byte) {
+	code := []byte(s.RestraintCode)
+	code[dof] = restraint
+	s.RestraintCode = string(code)
+	*s.spring(dof) = 0
+}
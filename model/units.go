@@ -0,0 +1,124 @@
+package model
+
+import (
+	"github.com/donniet/goframes/units"
+)
+
+// units of the model, imperial unless the settings say otherwise
+func (m *Skyciv) unitSystem() units.System {
+	if m.Settings.Units == nil {
+		return units.Imperial
+	}
+	return *m.Settings.Units
+}
+
+// ConvertUnits rescales every quantity in the model from its units to the
+// units of to, and sets the units of the model to to.  Goframes builds and
+// solves models in imperial units, so models are usually converted just
+// before they are exported.
+func (m *Skyciv) ConvertUnits(to units.System) error {
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	c, err := m.unitSystem().To(to)
+	if err != nil {
+		return err
+	}
+
+	length := c[units.KindLength]
+	section := c[units.KindSectionLength]
+	force := c[units.KindForce]
+
+	for _, n := range m.Nodes {
+		n.X *= length
+		n.Y *= length
+		n.Z *= length
+	}
+	for _, mem := range m.ContinuousMembers.members {
+		mem.OffsetA = mem.OffsetA.Scale(length)
+		mem.OffsetB = mem.OffsetB.Scale(length)
+	}
+	for _, s := range m.Sections {
+		s.Area *= section * section
+		s.Iy *= section * section * section * section
+		s.Iz *= section * section * section * section
+		s.J *= section * section * section * section
+	}
+	for _, mat := range m.Materials {
+		convertMaterial(mat, c)
+	}
+	for _, p := range m.Plates {
+		p.Thickness *= section
+		p.Offset *= section
+	}
+	// spring supports are in force per length and moment per radian
+	for _, s := range m.Supports {
+		s.Tx *= force / length
+		s.Ty *= force / length
+		s.Tz *= force / length
+		s.Rx *= c[units.KindMoment]
+		s.Ry *= c[units.KindMoment]
+		s.Rz *= c[units.KindMoment]
+	}
+
+	for _, pl := range m.PointLoads {
+		pl.Distance *= length
+		pl.Mag = pl.Mag.Scale(force)
+	}
+	for _, mo := range m.Moments {
+		mo.Mag = mo.Mag.Scale(c[units.KindMoment])
+	}
+	for _, dl := range m.DistributedLoads {
+		dl.Start *= length
+		dl.End *= length
+		dl.MagA = dl.MagA.Scale(force / length)
+		dl.MagB = dl.MagB.Scale(force / length)
+	}
+	for _, al := range m.AreaLoads {
+		al.Mag *= c[units.KindPressure]
+	}
+	for _, p := range m.Pressures {
+		p.XMag *= c[units.KindPressure]
+		p.YMag *= c[units.KindPressure]
+		p.ZMag *= c[units.KindPressure]
+	}
+
+	m.Settings.Units = &to
+	m.nodeIndex = nil
+	return nil
+}
+
+// convertMaterial rescales the strengths and density of a material
+func convertMaterial(mat *Material, c units.Conversion) {
+	strength := c[units.KindMaterialStrength]
+	mat.ElasticityModulus *= strength
+	mat.YieldStrength *= strength
+	mat.UltimateStrength *= strength
+	mat.ElasticityModulusX *= strength
+	mat.ElasticityModulusY *= strength
+	mat.ShearModulusXY *= strength
+	mat.ShearModulusXZ *= strength
+	mat.ShearModulusYZ *= strength
+	mat.Density *= c[units.KindDensity]
+}
+
+// ConvertUnits rescales the materials and design values of the file to the
+// units of to
+func (f *MaterialFile) ConvertUnits(to units.System) error {
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	c, err := f.Units.To(to)
+	if err != nil {
+		return err
+	}
+	for i := range f.Materials {
+		convertMaterial(&f.Materials[i], c)
+	}
+	strength := c[units.KindMaterialStrength]
+	for i := range f.Grades {
+		f.Grades[i].scale(strength)
+	}
+	f.Units = to
+	return nil
+}
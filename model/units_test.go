@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/donniet/goframes/units"
+)
+
+func TestConvertUnits(T *testing.T) {
+	m := portal()
+	m.NewPointLoad(m.FindNearestNode(0, 8, 0), "X", 2, "wind")
+	for _, mat := range m.Materials {
+		mat.ElasticityModulus = 1000
+		mat.Density = 30
+	}
+	if err := m.ConvertUnits(units.Metric); err != nil {
+		T.Fatal(err)
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) }
+	if n := m.FindNearestNode(3.048, 2.4384, 0); n == nil || !near(n.X, 3.048) {
+		T.Errorf("nodes not converted to m")
+	}
+	for _, dl := range m.DistributedLoads {
+		if !near(dl.End, 3.048) || !near(dl.MagA.Y, -14.593902937206364) {
+			T.Errorf("distributed load converted to %f kN/m to %f m", dl.MagA.Y, dl.End)
+		}
+	}
+	for _, pl := range m.PointLoads {
+		if !near(pl.Mag.X, 8.896443230521) {
+			T.Errorf("point load converted to %f kN", pl.Mag.X)
+		}
+	}
+	for _, mat := range m.Materials {
+		if !near(mat.ElasticityModulus, 6894.757293168) || !near(mat.Density, 480.5539012188) {
+			T.Errorf("material converted to %f MPa, %f kg/m3", mat.ElasticityModulus, mat.Density)
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"units":"metric"`) {
+		T.Errorf("metric model exported without metric units")
+	}
+}
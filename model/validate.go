@@ -0,0 +1,454 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Severity of a diagnostic.  Models with errors will be rejected or
+// mis-analyzed by skyciv, warnings are suspicious but analyzable.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Checks reported by Validate
+const (
+	CheckNoSupports        = "no_supports"
+	CheckDisconnected      = "disconnected"
+	CheckUnconnectedNode   = "unconnected_node"
+	CheckMechanism         = "mechanism"
+	CheckFreeJoint         = "free_joint"
+	CheckZeroLength        = "zero_length"
+	CheckAreaLoad          = "area_load"
+	CheckMissingMaterial   = "missing_material"
+	CheckUnmappedLoadGroup = "unmapped_load_group"
+)
+
+// Diagnostic is a single problem found by Validate.  Id is the id of the
+// member segment, area load or section the check is about, or 0 when the check
+// is about nodes alone.
+type Diagnostic struct {
+	Severity Severity
+	Check    string
+	Message  string
+	Id       int
+	Nodes    []int
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Check, d.Message)
+}
+
+type Diagnostics []Diagnostic
+
+// Errors returns only the diagnostics with SeverityError
+func (ds Diagnostics) Errors() (ret Diagnostics) {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			ret = append(ret, d)
+		}
+	}
+	return
+}
+
+// Err returns an error listing every error diagnostic, or nil if there are
+// none
+func (ds Diagnostics) Err() error {
+	errs := ds.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, d := range errs {
+		msgs[i] = d.Check + ": " + d.Message
+	}
+	return fmt.Errorf("model is not valid: %s", strings.Join(msgs, "; "))
+}
+
+// Validate checks the model for problems that would make skyciv reject it or
+// make its results meaningless.  Stability is checked against rigid body
+// motion of each connected part and joints that are free to rotate, other
+// mechanisms are only found by solving.
+func (m *Skyciv) Validate() (ds Diagnostics) {
+	report := func(sev Severity, check string, id int, nodes []int, format string, args ...interface{}) {
+		ds = append(ds, Diagnostic{
+			Severity: sev,
+			Check:    check,
+			Message:  fmt.Sprintf(format, args...),
+			Id:       id,
+			Nodes:    nodes,
+		})
+	}
+
+	segments := m.ContinuousMembers.Segments()
+
+	for _, s := range segments {
+		if Distance(s.A, s.B) < colocatedEps {
+			report(SeverityError, CheckZeroLength, s.Id, []int{s.A.Id, s.B.Id}, "member %d has zero length", s.Id)
+		}
+	}
+
+	sectionIds := make([]int, 0, len(m.Sections))
+	for id := range m.Sections {
+		sectionIds = append(sectionIds, id)
+	}
+	sort.Ints(sectionIds)
+	for _, id := range sectionIds {
+		if sec := m.Sections[id]; m.Materials.ById(sec.MaterialId) == nil {
+			report(SeverityError, CheckMissingMaterial, id, nil, "section %d references missing material %d", id, sec.MaterialId)
+		}
+	}
+
+	m.validateStability(segments, report)
+	m.validateAreaLoads(report)
+	m.validateLoadGroups(report)
+	return
+}
+
+type reporter func(sev Severity, check string, id int, nodes []int, format string, args ...interface{})
+
+// components groups the nodes connected by members and plates.  Nodes not used
+// by any member or plate are returned separately.
+func (m *Skyciv) components(segments []Segment) (parts [][]*Node, unconnected []*Node) {
+	parent := make(map[*Node]*Node)
+	var find func(n *Node) *Node
+	find = func(n *Node) *Node {
+		if parent[n] != n {
+			parent[n] = find(parent[n])
+		}
+		return parent[n]
+	}
+	join := func(a, b *Node) {
+		for _, n := range []*Node{a, b} {
+			if _, ok := parent[n]; !ok {
+				parent[n] = n
+			}
+		}
+		parent[find(a)] = find(b)
+	}
+
+	for _, s := range segments {
+		join(s.A, s.B)
+	}
+	for _, p := range m.Plates {
+		for _, id := range p.Nodes[1:] {
+			if a, b := m.Nodes[p.Nodes[0]], m.Nodes[id]; a != nil && b != nil {
+				join(a, b)
+			}
+		}
+	}
+
+	var roots []*Node
+	byRoot := make(map[*Node][]*Node)
+	for _, id := range m.sortedNodeIds() {
+		n := m.Nodes[id]
+		if _, ok := parent[n]; !ok {
+			unconnected = append(unconnected, n)
+			continue
+		}
+		r := find(n)
+		if _, ok := byRoot[r]; !ok {
+			roots = append(roots, r)
+		}
+		byRoot[r] = append(byRoot[r], n)
+	}
+	for _, r := range roots {
+		parts = append(parts, byRoot[r])
+	}
+	return
+}
+
+func (m *Skyciv) sortedNodeIds() []int {
+	ids := make([]int, 0, len(m.Nodes))
+	for id := range m.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// restrains reports whether a restraint code character resists motion
+func restrains(c byte) bool {
+	return c == 'F' || c == 'S'
+}
+
+func (m *Skyciv) validateStability(segments []Segment, report reporter) {
+	parts, unconnected := m.components(segments)
+	for _, n := range unconnected {
+		report(SeverityWarning, CheckUnconnectedNode, 0, []int{n.Id}, "node %d is not connected to any member or plate", n.Id)
+	}
+
+	m.validateJoints(segments, report)
+
+	if len(m.Supports) == 0 {
+		report(SeverityError, CheckNoSupports, 0, nil, "model has no supports")
+		return
+	}
+
+	for _, part := range parts {
+		ids := NodeList(part).NodeIds()
+
+		// each restrained dof removes a combination of the rigid body motion
+		// u = t + w x p of the part
+		var rows [][6]float64
+		for _, n := range part {
+			sup := n.Support()
+			if sup == nil {
+				continue
+			}
+			p := n.ToVector()
+			for i := 0; i < 3 && i < len(sup.RestraintCode); i++ {
+				if !restrains(sup.RestraintCode[i]) {
+					continue
+				}
+				var e Vector
+				switch i {
+				case 0:
+					e.X = 1
+				case 1:
+					e.Y = 1
+				case 2:
+					e.Z = 1
+				}
+				// e . (w x p) == w . (p x e)
+				w := p.Cross(e)
+				rows = append(rows, [6]float64{e.X, e.Y, e.Z, w.X, w.Y, w.Z})
+			}
+			for i := 3; i < 6 && i < len(sup.RestraintCode); i++ {
+				if restrains(sup.RestraintCode[i]) {
+					var r [6]float64
+					r[i] = 1
+					rows = append(rows, r)
+				}
+			}
+		}
+
+		if len(rows) == 0 {
+			report(SeverityError, CheckDisconnected, 0, ids, "%d nodes starting at node %d are not connected to any support", len(part), part[0].Id)
+			continue
+		}
+		if r := rank(rows); r < 6 {
+			report(SeverityError, CheckMechanism, 0, ids, "supports of the %d nodes starting at node %d leave %d rigid body motions free", len(part), part[0].Id, 6-r)
+		}
+	}
+}
+
+// validateJoints finds nodes where every member end is released so nothing
+// resists rotation of the node itself
+func (m *Skyciv) validateJoints(segments []Segment, report reporter) {
+	axes := make(map[*Node][]Vector)
+	for _, s := range segments {
+		if Distance(s.A, s.B) < colocatedEps {
+			continue
+		}
+		x, y, z := LocalAxes(s.A.ToVector(), s.B.ToVector(), s.Member.RotationAngle)
+		local := []Vector{x, y, z}
+		for _, end := range []struct {
+			n      *Node
+			fixity string
+		}{{s.A, s.FixityA}, {s.B, s.FixityB}} {
+			if _, ok := axes[end.n]; !ok {
+				axes[end.n] = nil
+			}
+			for i := 3; i < 6 && i < len(end.fixity); i++ {
+				if end.fixity[i] == 'F' {
+					axes[end.n] = append(axes[end.n], local[i-3])
+				}
+			}
+		}
+	}
+
+	for _, id := range m.sortedNodeIds() {
+		n := m.Nodes[id]
+		dirs, ok := axes[n]
+		if !ok {
+			continue
+		}
+		if sup := n.Support(); sup != nil {
+			global := []Vector{{X: 1}, {Y: 1}, {Z: 1}}
+			for i := 3; i < 6 && i < len(sup.RestraintCode); i++ {
+				if restrains(sup.RestraintCode[i]) {
+					dirs = append(dirs, global[i-3])
+				}
+			}
+		}
+
+		rows := make([][6]float64, len(dirs))
+		for i, d := range dirs {
+			rows[i] = [6]float64{d.X, d.Y, d.Z}
+		}
+		if rank(rows) < 3 {
+			report(SeverityError, CheckFreeJoint, 0, []int{id}, "node %d is free to rotate, every member is released there", id)
+		}
+	}
+}
+
+// rank of the matrix by gaussian elimination with partial pivoting
+func rank(rows [][6]float64) int {
+	a := make([][6]float64, len(rows))
+	copy(a, rows)
+
+	// scale the tolerance to the largest coordinate, since rotations about
+	// the origin grow with distance
+	scale := 0.
+	for _, r := range a {
+		for _, v := range r {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	tol := 1e-9 * math.Max(scale, 1)
+
+	r := 0
+	for c := 0; c < 6 && r < len(a); c++ {
+		p := r
+		for i := r + 1; i < len(a); i++ {
+			if math.Abs(a[i][c]) > math.Abs(a[p][c]) {
+				p = i
+			}
+		}
+		if math.Abs(a[p][c]) <= tol {
+			continue
+		}
+		a[r], a[p] = a[p], a[r]
+		for i := r + 1; i < len(a); i++ {
+			f := a[i][c] / a[r][c]
+			for j := c; j < 6; j++ {
+				a[i][j] -= f * a[r][j]
+			}
+		}
+		r++
+	}
+	return r
+}
+
+func (m *Skyciv) validateAreaLoads(report reporter) {
+	ids := make([]int, 0, len(m.AreaLoads))
+	for id := range m.AreaLoads {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		al := m.AreaLoads[id]
+		nodes := []int(al.Nodes)
+
+		var pts []Vector
+		seen := make(map[int]bool)
+		missing, repeated := false, false
+		for _, nid := range al.Nodes {
+			n, ok := m.Nodes[nid]
+			if !ok {
+				missing = true
+				continue
+			}
+			if seen[nid] {
+				repeated = true
+			}
+			seen[nid] = true
+			pts = append(pts, n.ToVector())
+		}
+
+		switch {
+		case missing:
+			report(SeverityError, CheckAreaLoad, id, nodes, "area load %d references missing nodes", id)
+		case repeated:
+			report(SeverityError, CheckAreaLoad, id, nodes, "area load %d visits a node twice", id)
+		case len(pts) < 3:
+			report(SeverityError, CheckAreaLoad, id, nodes, "area load %d has fewer than 3 nodes", id)
+		case !coplanar(pts):
+			report(SeverityError, CheckAreaLoad, id, nodes, "area load %d nodes are not coplanar", id)
+		case !simplePolygon(pts):
+			report(SeverityError, CheckAreaLoad, id, nodes, "area load %d nodes do not form a closed polygon, its edges cross", id)
+		}
+	}
+}
+
+// simplePolygon reports whether the edges of the planar polygon only meet at
+// shared corners
+func simplePolygon(pts []Vector) bool {
+	n := len(pts)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			// adjacent edges share a corner
+			if j == i+1 || (i == 0 && j == n-1) {
+				continue
+			}
+			a0, a1 := pts[i], pts[(i+1)%n]
+			b0, b1 := pts[j], pts[(j+1)%n]
+			s, t, ok := closestPoints(a0, a1, b0, b1)
+			if !ok {
+				// parallel edges only touch if they overlap
+				if _, d := distanceToSegment(b0, a0, a1); d < colocatedEps {
+					return false
+				}
+				continue
+			}
+			if lerp(a0, a1, s).Diff(lerp(b0, b1, t)).Length() < colocatedEps {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// distanceToSegment finds the parameter and distance of the closest point to p
+// on the segment a-b
+func distanceToSegment(p, a, b Vector) (t, d float64) {
+	ab := b.Diff(a)
+	t = math.Max(0, math.Min(1, p.Diff(a).Dot(ab)/ab.Dot(ab)))
+	return t, p.Diff(lerp(a, b, t)).Length()
+}
+
+func (m *Skyciv) validateLoadGroups(report reporter) {
+	if len(m.LoadCombinations.Cases) == 0 {
+		return
+	}
+
+	mapped := make(map[string]bool)
+	for _, g := range m.LoadCombinations.Mapping.Groups() {
+		mapped[g] = true
+	}
+
+	used := make(map[string]bool)
+	for _, al := range m.AreaLoads {
+		used[al.LoadGroup] = true
+	}
+	for _, sw := range m.SelfWeight {
+		used[sw.LoadGroup] = true
+	}
+	for _, pl := range m.PointLoads {
+		used[pl.LoadGroup] = true
+	}
+	for _, mo := range m.Moments {
+		used[mo.LoadGroup] = true
+	}
+	for _, dl := range m.DistributedLoads {
+		used[dl.LoadGroup] = true
+	}
+	for _, pr := range m.Pressures {
+		used[pr.LoadGroup] = true
+	}
+
+	groups := make([]string, 0, len(used))
+	for g := range used {
+		if !mapped[g] {
+			groups = append(groups, g)
+		}
+	}
+	sort.Strings(groups)
+	for _, g := range groups {
+		report(SeverityError, CheckUnmappedLoadGroup, 0, nil, "load group %q is not in any load combination", g)
+	}
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"testing"
+)
+
+// checks lists the check of each diagnostic
+func checks(ds Diagnostics) (ret []string) {
+	for _, d := range ds {
+		ret = append(ret, d.Check)
+	}
+	return
+}
+
+func hasCheck(ds Diagnostics, check string) bool {
+	for _, d := range ds {
+		if d.Check == check {
+			return true
+		}
+	}
+	return false
+}
+
+// portal builds a fixed base portal frame loaded on its roof
+func portal() *Skyciv {
+	m := NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "test")
+	left := m.NewContinuousMember(sec, 0, 0, 0, 0, 8, 0)
+	right := m.NewContinuousMember(sec, 10, 0, 0, 10, 8, 0)
+	m.NewContinuousMember(sec, 0, 8, 0, 10, 8, 0)
+	left.Begin().FixedSupport()
+	right.Begin().FixedSupport()
+	m.NewDistributedLoad(m.ContinuousMembers.Members()[2], 0, 10, "Y", -1, -1, "dead")
+	m.LoadCombinations.Mapping.DeadCases("dead")
+	m.LoadCombinations.Cases = []Case{{Name: "1.4D", Dead: 1.4}}
+	return m
+}
+
+func TestValidate(T *testing.T) {
+	m := portal()
+	if ds := m.Validate(); len(ds) != 0 || ds.Err() != nil {
+		T.Fatalf("valid portal reported %v", checks(ds))
+	}
+
+	// pinned bases let a planar frame fall over about the line through them
+	for _, sup := range m.Supports {
+		sup.RestraintCode = RestraintPinned
+	}
+	if ds := m.Validate(); !hasCheck(ds, CheckMechanism) {
+		T.Errorf("pinned portal not reported as a mechanism: %v", checks(ds))
+	}
+
+	// one fixed base is enough to hold it
+	for _, sup := range m.Supports {
+		sup.RestraintCode = RestraintFixed
+		break
+	}
+	if ds := m.Validate(); len(ds) != 0 {
+		T.Errorf("portal with a fixed base reported %v", checks(ds))
+	}
+
+	for _, sup := range m.Supports {
+		m.RemoveSupport(sup)
+	}
+	if ds := m.Validate(); !hasCheck(ds, CheckNoSupports) || ds.Err() == nil {
+		T.Errorf("portal without supports not reported: %v", checks(ds))
+	}
+}
+
+func TestValidateProblems(T *testing.T) {
+	m := portal()
+	sec := m.ContinuousMembers.Members()[0].Section()
+
+	// a floating beam, a cantilever with a released tip and a stray node
+	m.NewContinuousMember(sec, 0, 0, 20, 10, 0, 20)
+	tip := m.NewContinuousMember(sec, 0, 8, 0, 0, 8, 4)
+	tip.FixityB = FixityPinned
+	m.NewNode(5, 5, 5)
+
+	// a zero length member
+	zero := m.NewContinuousMember(sec, 10, 8, 0, 10, 9, 0)
+	zero.nodes[1] = zero.nodes[0]
+
+	// a bowtie and a warped area load
+	a, b, c, d := m.NewNode(0, 8, 0), m.NewNode(10, 8, 0), m.NewNode(10, 8, 4), m.NewNode(0, 8, 4)
+	bowtie, _ := m.NewAreaLoad(a, c, b, d)
+	bowtie.LoadGroup = "dead"
+	warped, _ := m.NewAreaLoad(a, b, m.NewNode(10, 9, 4), d)
+	warped.LoadGroup = "wind"
+
+	missing := m.NewSectionFromLibrary(&Material{Id: 99}, "test")
+
+	ds := m.Validate()
+	for _, check := range []string{
+		CheckDisconnected, CheckFreeJoint, CheckUnconnectedNode, CheckZeroLength,
+		CheckAreaLoad, CheckMissingMaterial, CheckUnmappedLoadGroup,
+	} {
+		if !hasCheck(ds, check) {
+			T.Errorf("%s not reported: %v", check, checks(ds))
+		}
+	}
+	for _, d := range ds {
+		switch d.Check {
+		case CheckUnconnectedNode:
+			if d.Severity != SeverityWarning {
+				T.Errorf("unconnected node is not a warning")
+			}
+		case CheckAreaLoad:
+			if d.Id != bowtie.Id && d.Id != warped.Id {
+				T.Errorf("area load %d reported", d.Id)
+			}
+		case CheckMissingMaterial:
+			if d.Id != missing.Id {
+				T.Errorf("section %d reported missing a material", d.Id)
+			}
+		case CheckUnmappedLoadGroup:
+			if d.Message != `load group "wind" is not in any load combination` {
+				T.Errorf("unexpected message %q", d.Message)
+			}
+		case CheckFreeJoint:
+			if len(d.Nodes) != 1 || d.Nodes[0] != tip.End().Id {
+				T.Errorf("free joint reported at %v instead of the tip", d.Nodes)
+			}
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package nds
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/donniet/goframes/model"
+	"github.com/donniet/goframes/results"
+	"github.com/donniet/goframes/solver"
+	"github.com/donniet/goframes/units"
+)
+
+var ErrUnits = errors.New("model is not in imperial units")
+
+// Forces are the largest internal forces along a segment in kip and kip-ft.
+// Tension and Compression are both positive.
+type Forces struct {
+	Tension, Compression float64
+	ShearY, ShearZ       float64
+	MomentY, MomentZ     float64
+}
+
+// peakMoment is the largest magnitude of m0 + v x + q x^2 / 2 over 0 to l
+func peakMoment(m0, v, q, l float64) float64 {
+	at := func(x float64) float64 { return math.Abs(m0 + v*x + q*x*x/2) }
+	peak := math.Max(at(0), at(l))
+	if q != 0 {
+		if x := -v / q; x > 0 && x < l {
+			peak = math.Max(peak, at(x))
+		}
+	}
+	return peak
+}
+
+// SolverForces finds the forces along a segment from its end forces.  The
+// solver only reports the ends so the load between them is taken as uniform,
+// which finds the peak moment of distributed loads and self weight.
+func SolverForces(mf *solver.MemberForces) Forces {
+	a, b := mf.A, mf.B
+	l := model.Distance(mf.Segment.A, mf.Segment.B)
+	// the end forces act on the segment, so the internal axial force is the
+	// pull of B less the push of A
+	na, nb := -a.Axial, b.Axial
+	wy := -(a.ShearY + b.ShearY) / l
+	wz := -(a.ShearZ + b.ShearZ) / l
+	return Forces{
+		Tension:     math.Max(0, math.Max(na, nb)),
+		Compression: math.Max(0, -math.Min(na, nb)),
+		ShearY:      math.Max(math.Abs(a.ShearY), math.Abs(b.ShearY)),
+		ShearZ:      math.Max(math.Abs(a.ShearZ), math.Abs(b.ShearZ)),
+		MomentZ:     peakMoment(-a.MomentZ, a.ShearY, wy, l),
+		MomentY:     peakMoment(-a.MomentY, -a.ShearZ, -wz, l),
+	}
+}
+
+// SkycivForces finds the forces along a segment from the skyciv results of
+// its member, where axial forces are positive in tension
+func SkycivForces(r *results.MemberResult) Forces {
+	axial := r.Peak(results.AxialForce)
+	return Forces{
+		Tension:     math.Max(0, axial.Max),
+		Compression: math.Max(0, -axial.Min),
+		ShearY:      r.Peak(results.ShearForceY).AbsMax(),
+		ShearZ:      r.Peak(results.ShearForceZ).AbsMax(),
+		MomentY:     r.Peak(results.BendingMomentY).AbsMax(),
+		MomentZ:     r.Peak(results.BendingMomentZ).AbsMax(),
+	}
+}
+
+// Utilization is the ratio of the stresses in a segment to its adjusted design
+// values for one load combination
+type Utilization struct {
+	Segment model.Segment
+	Case    string
+	Adjusted
+	// Combined is the bending and axial interaction, equation 3.9-1 in
+	// tension or 3.9-3 in compression, and is infinite when the member
+	// buckles or is too slender
+	Combined float64
+	// Compression is the axial compression alone, which the squared term of
+	// the interaction understates
+	Compression float64
+	Shear       float64
+}
+
+// Max is the governing ratio
+func (u Utilization) Max() float64 {
+	return math.Max(u.Combined, math.Max(u.Compression, u.Shear))
+}
+
+// interaction is the bending and axial interaction of 3.9 of the forces f on
+// a section of size s
+func interaction(f Forces, s Size, a Adjusted) float64 {
+	fbz := f.MomentZ * 12 / s.Sz()
+	fby := f.MomentY * 12 / s.Sy()
+
+	tension := 0.
+	if f.Tension > 0 {
+		ft := f.Tension / s.Area()
+		tension = ft/a.Ft + fbz/a.FbzStar + fby/a.Fby
+		// the compression edge with the tension taken off
+		tension = math.Max(tension, (fbz-ft)/a.Fbz+fby/a.Fby)
+	}
+
+	fc := f.Compression / s.Area()
+	if (fc > 0 && a.Slenderness > 50) || (fbz > 0 && a.RB > 50) {
+		return math.Inf(1)
+	}
+	if fc >= a.FcEz || fc >= a.FcEy || fbz >= a.FbE {
+		return math.Inf(1)
+	}
+	z := 1 - fc/a.FcEz
+	y := 1 - fc/a.FcEy - math.Pow(fbz/a.FbE, 2)
+	if y <= 0 {
+		return math.Inf(1)
+	}
+	compression := math.Pow(fc/a.Fc, 2) + fbz/(a.Fbz*z) + fby/(a.Fby*y)
+	return math.Max(tension, compression)
+}
+
+// Options of a design check
+type Options struct {
+	Method  Method
+	Service Service
+	// Values finds the reference design values of a section
+	Values func(*model.Section) (*model.DesignValues, error)
+	// Size finds the dressed size of a section and defaults to SawnSize
+	Size func(*model.Section) (Size, error)
+	// BracedLength is the length in ft of a segment between braces, which
+	// defaults to the length of the segment
+	BracedLength func(model.Segment) float64
+}
+
+// FileValues finds the design values of a grade of the material of each
+// section in a materials file
+func FileValues(f *model.MaterialFile, m *model.Skyciv, grade string) func(*model.Section) (*model.DesignValues, error) {
+	return func(sec *model.Section) (*model.DesignValues, error) {
+		mat := m.Materials.ById(sec.MaterialId)
+		if mat == nil {
+			return nil, fmt.Errorf("section %d references missing material %d", sec.Id, sec.MaterialId)
+		}
+		return f.Grade(mat.Name, grade)
+	}
+}
+
+// check rates the forces of one segment under one combination
+func (o Options) check(seg model.Segment, c model.Case, f Forces) (Utilization, error) {
+	sec := seg.Member.Section()
+	v, err := o.Values(sec)
+	if err != nil {
+		return Utilization{}, err
+	}
+	s, err := o.Size(sec)
+	if err != nil {
+		return Utilization{}, err
+	}
+	l := model.Distance(seg.A, seg.B)
+	if o.BracedLength != nil {
+		l = o.BracedLength(seg)
+	}
+	a, err := Adjust(v, s, l, c, o.Method, o.Service)
+	if err != nil {
+		return Utilization{}, fmt.Errorf("segment %d: %v", seg.Id, err)
+	}
+	return Utilization{
+		Segment:     seg,
+		Case:        c.Name,
+		Adjusted:    a,
+		Combined:    interaction(f, s, a),
+		Compression: f.Compression / s.Area() / a.Fc,
+		Shear:       1.5 * math.Max(f.ShearY, f.ShearZ) / s.Area() / a.Fv,
+	}, nil
+}
+
+// caseForces are the forces of every segment under a combination
+type caseForces struct {
+	name   string
+	forces map[int]Forces
+}
+
+func (o Options) checkAll(m *model.Skyciv, cases []caseForces) ([]Utilization, error) {
+	if u := m.Settings.Units; u != nil {
+		if c, err := u.To(units.Imperial); err != nil || !c.Identity() {
+			return nil, ErrUnits
+		}
+	}
+	if o.Values == nil {
+		return nil, fmt.Errorf("design checks need the design values of each section")
+	}
+	if o.Size == nil {
+		o.Size = SawnSize
+	}
+	combinations := make(map[string]model.Case)
+	for _, c := range m.LoadCombinations.Cases {
+		combinations[c.Name] = c
+	}
+	segments := m.ContinuousMembers.Segments()
+
+	var ret []Utilization
+	for _, cf := range cases {
+		c, ok := combinations[cf.name]
+		if !ok {
+			return nil, fmt.Errorf("no load combination named %q", cf.name)
+		}
+		for _, seg := range segments {
+			f, ok := cf.forces[seg.Id]
+			if !ok {
+				continue
+			}
+			u, err := o.check(seg, c, f)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, u)
+		}
+	}
+	return ret, nil
+}
+
+// CheckSolver rates every segment under every load combination of the
+// results of the solver
+func CheckSolver(m *model.Skyciv, res *solver.Results, o Options) ([]Utilization, error) {
+	cases := make([]caseForces, len(res.Cases))
+	for i, cr := range res.Cases {
+		cases[i] = caseForces{cr.Name, make(map[int]Forces)}
+		for id, mf := range cr.Members {
+			cases[i].forces[id] = SolverForces(mf)
+		}
+	}
+	return o.checkAll(m, cases)
+}
+
+// CheckSkyciv rates every segment under every load combination of the results
+// of skyciv, which must have been parsed with the model
+func CheckSkyciv(m *model.Skyciv, res *results.Results, o Options) ([]Utilization, error) {
+	ids := make(map[*model.ContinuousMember]map[int]int)
+	for _, seg := range m.ContinuousMembers.Segments() {
+		if ids[seg.Member] == nil {
+			ids[seg.Member] = make(map[int]int)
+		}
+		ids[seg.Member][seg.Index] = seg.Id
+	}
+
+	cases := make([]caseForces, len(res.Cases))
+	for i, c := range res.Cases {
+		cases[i] = caseForces{c.Name, make(map[int]Forces)}
+		for _, r := range c.Members {
+			id, ok := ids[r.Member][r.Index]
+			if !ok {
+				return nil, fmt.Errorf("skyciv member %d is not a segment of the model", r.Id)
+			}
+			cases[i].forces[id] = SkycivForces(r)
+		}
+	}
+	return o.checkAll(m, cases)
+}
+
+// Governing keeps the combination with the highest utilization of each
+// segment, ordered from the most utilized
+func Governing(us []Utilization) []Utilization {
+	worst := make(map[int]Utilization)
+	for _, u := range us {
+		if w, ok := worst[u.Segment.Id]; !ok || u.Max() > w.Max() {
+			worst[u.Segment.Id] = u
+		}
+	}
+	ret := make([]Utilization, 0, len(worst))
+	for _, u := range worst {
+		ret = append(ret, u)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Max() != ret[j].Max() {
+			return ret[i].Max() > ret[j].Max()
+		}
+		return ret[i].Segment.Id < ret[j].Segment.Id
+	})
+	return ret
+}
@@ -0,0 +1,361 @@
+// Package nds checks wood members against the National Design Specification
+// for Wood Construction.  Reference design values are adjusted for load
+// duration, wet service, temperature, size, beam and column stability and
+// volume, then compared to the stresses from the member forces of the solver
+// or of skyciv.
+//
+// Like the design values in materials.json the package works in imperial
+// units: stresses in ksi, section dimensions in in, lengths in ft, forces in
+// kip and moments in kip-ft.
+package nds
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/donniet/goframes/model"
+)
+
+// Method is the format of the design: allowable stress design adjusts values
+// for load duration with CD, load and resistance factor design converts them
+// to resistances with KF, phi and the time effect factor lambda
+type Method int
+
+const (
+	ASD Method = iota
+	LRFD
+)
+
+// Service conditions of the members
+type Service struct {
+	// Wet service is a moisture content over 19% for sawn lumber and 16% for
+	// glulam
+	Wet bool
+	// Temperature is the sustained temperature of the members in F, up to
+	// 150.  Zero is taken as normal temperatures up to 100 F.
+	Temperature float64
+}
+
+// Size is the dressed size of a rectangular section in in.  D is the depth
+// along the local y axis of the member, which resists bending about z.
+type Size struct {
+	B, D float64
+}
+
+func (s Size) Area() float64 { return s.B * s.D }
+
+// Sz and Sy are the section moduli for bending about the local z and y axes
+func (s Size) Sz() float64 { return s.B * s.D * s.D / 6 }
+func (s Size) Sy() float64 { return s.D * s.B * s.B / 6 }
+
+// timber is true for sawn timbers 5 in and larger, which have their own wet
+// service and size factors
+func (s Size) timber() bool {
+	return s.B >= 4.5 && s.D >= 4.5
+}
+
+// beam is true for timbers that are beams and stringers, more than 2 in
+// deeper than they are thick, and false for posts and timbers
+func (s Size) beam() bool {
+	return s.D-s.B > 2
+}
+
+// nominal is the nominal width of dimension lumber d in deep
+func nominal(d float64) float64 {
+	if d > 6 {
+		return math.Round(d + 0.75)
+	}
+	return math.Round(d + 0.5)
+}
+
+// SawnSize is the standard dressed size of a sawn lumber library section from
+// its nominal size
+func SawnSize(sec *model.Section) (Size, error) {
+	b, d, err := model.SawnLumberSize(sec.NominalSize())
+	if err != nil {
+		return Size{}, fmt.Errorf("section %d: %v", sec.Id, err)
+	}
+	return Size{B: b, D: d}, nil
+}
+
+// LoadDuration is the load duration factor CD of a load combination, table
+// 2.3.2, set by the shortest duration load in it
+func LoadDuration(c model.Case) float64 {
+	switch {
+	case c.Wind != 0 || c.Seismic != 0:
+		return 1.6
+	case c.RoofLive != 0:
+		return 1.25
+	case c.Snow != 0:
+		return 1.15
+	case c.Live != 0:
+		return 1.0
+	}
+	return 0.9
+}
+
+// TimeEffect is the LRFD time effect factor lambda of a load combination,
+// table N3, taking live loads as occupancy loads
+func TimeEffect(c model.Case) float64 {
+	switch {
+	case c.Wind != 0 || c.Seismic != 0:
+		return 1.0
+	case c.Live != 0 || c.RoofLive != 0 || c.Snow != 0:
+		return 0.8
+	}
+	return 0.6
+}
+
+// factors are the adjustment factors that apply to one design value
+type factors struct {
+	// time is CD in ASD and KF phi lambda in LRFD
+	time float64
+	CM   float64
+	Ct   float64
+	CF   float64
+}
+
+func (f factors) apply(v float64) float64 {
+	return v * f.time * f.CM * f.Ct * f.CF
+}
+
+// wetService is the wet service factor CM of each design value, tables 4A,
+// 4D and 5A.  Bending and compression values that are low after their size
+// factors cfb and cfc are not reduced.
+func wetService(v *model.DesignValues, s Size, cfb, cfc float64) (fb, ft, fv, fc, e float64) {
+	switch {
+	case v.Glulam:
+		return 0.8, 0.8, 0.875, 0.73, 0.833
+	case s.timber():
+		return 1, 1, 1, 0.91, 1
+	}
+	fb, fc = 0.85, 0.8
+	if v.Fb*cfb <= 1.15 {
+		fb = 1
+	}
+	if v.Fc*cfc <= 0.75 {
+		fc = 1
+	}
+	return fb, 1, 0.97, fc, 0.9
+}
+
+// temperature is the temperature factor Ct of tension and stiffness and of
+// the other design values, table 2.3.3
+func temperature(s Service) (tension, other float64, err error) {
+	switch t := s.Temperature; {
+	case t <= 100:
+		return 1, 1, nil
+	case t <= 125:
+		if s.Wet {
+			return 0.9, 0.7, nil
+		}
+		return 0.9, 0.8, nil
+	case t <= 150:
+		if s.Wet {
+			return 0.9, 0.5, nil
+		}
+		return 0.9, 0.7, nil
+	}
+	return 0, 0, fmt.Errorf("no temperature factors over 150 F")
+}
+
+// sizeFactor is the size factor CF of visually graded dimension lumber for
+// bending, tension and compression, table 4A, and of timbers deeper than
+// 12 in for bending, table 4D
+func sizeFactor(v *model.DesignValues, s Size) (fb, ft, fc float64) {
+	if v.Glulam {
+		return 1, 1, 1
+	}
+	if s.timber() {
+		if s.D > 12 {
+			return math.Pow(12/s.D, 1./9.), 1, 1
+		}
+		return 1, 1, 1
+	}
+
+	// rows are nominal widths with bending for 2 and 3 in and for 4 in thick
+	// lumber, tension and compression
+	var row [4]float64
+	switch w := nominal(s.D); {
+	case w <= 4:
+		row = [4]float64{1.5, 1.5, 1.5, 1.15}
+	case w <= 5:
+		row = [4]float64{1.4, 1.4, 1.4, 1.1}
+	case w <= 6:
+		row = [4]float64{1.3, 1.3, 1.3, 1.1}
+	case w <= 8:
+		row = [4]float64{1.2, 1.3, 1.2, 1.05}
+	case w <= 10:
+		row = [4]float64{1.1, 1.2, 1.1, 1.0}
+	case w <= 12:
+		row = [4]float64{1.0, 1.1, 1.0, 1.0}
+	default:
+		row = [4]float64{0.9, 1.0, 0.9, 0.9}
+	}
+	fb = row[0]
+	if s.B > 3 {
+		fb = row[1]
+	}
+	return fb, row[2], row[3]
+}
+
+// flatUse is the flat use factor Cfu of bending on the wide face, about the
+// local y axis, of dimension lumber, table 4A, and of beams and stringers,
+// table 4D
+func flatUse(v *model.DesignValues, s Size) float64 {
+	switch {
+	case v.Glulam || s.D <= s.B:
+		return 1
+	case s.timber():
+		if v.Cfu > 0 {
+			return v.Cfu
+		}
+		return 1
+	}
+
+	// columns are 2 and 3 in and 4 in thick lumber
+	var row [2]float64
+	switch w := nominal(s.D); {
+	case w <= 3:
+		row = [2]float64{1, 1}
+	case w <= 4:
+		row = [2]float64{1.1, 1}
+	case w <= 5:
+		row = [2]float64{1.1, 1.05}
+	case w <= 8:
+		row = [2]float64{1.15, 1.05}
+	default:
+		row = [2]float64{1.2, 1.1}
+	}
+	if s.B > 3 {
+		return row[1]
+	}
+	return row[0]
+}
+
+// timberValues are the table 4D values of a grade for timbers of size s, as
+// beams and stringers or as posts and timbers
+func timberValues(v *model.DesignValues, s Size) (*model.DesignValues, error) {
+	t, use := v.PostsAndTimbers, "posts and timbers"
+	if s.beam() {
+		t, use = v.BeamsAndStringers, "beams and stringers"
+	}
+	if t == nil {
+		return nil, fmt.Errorf("no table 4D values of %s %s %s", v.Grade, v.Material, use)
+	}
+	return t, nil
+}
+
+// volumeFactor is the volume factor CV of glulam beams l ft long, 5.3.6
+func volumeFactor(s Size, l float64) float64 {
+	cv := math.Pow(21/l, 0.1) * math.Pow(12/s.D, 0.1) * math.Pow(5.125/s.B, 0.1)
+	return math.Min(cv, 1)
+}
+
+// beamLength is the effective length le in in of a beam with an unbraced
+// length of lu in, table 3.3.3 for any loading
+func beamLength(lu, d float64) float64 {
+	switch r := lu / d; {
+	case r < 7:
+		return 2.06 * lu
+	case r <= 14.3:
+		return 1.63*lu + 3*d
+	}
+	return 1.84 * lu
+}
+
+// stability is the beam stability factor CL, equation 3.3-6, and the column
+// stability factor CP, equation 3.7-1, which share their form
+func stability(ratio, c float64) float64 {
+	a := (1 + ratio) / (2 * c)
+	return a - math.Sqrt(a*a-ratio/c)
+}
+
+// Adjusted are the adjusted design values of a member for one load
+// combination, in ksi.  Bending values are about the local z axis, edgewise,
+// and y axis, flatwise.
+type Adjusted struct {
+	// CD is the load duration factor, 1 in LRFD, and CM, Ct and CF are the
+	// factors on bending.  Cfu applies only to Fby.
+	CD, CM, Ct, CF, Cfu, CL, CP, CV float64
+
+	Fbz, Fby, Ft, Fv, Fc, Emin float64
+	// FbE is the critical buckling value for bending and FcEz and FcEy for
+	// compression buckling in the plane of bending about each axis
+	FbE, FcEz, FcEy float64
+	// Fbz without CL and CV, for tension and bending
+	FbzStar float64
+	// RB is the slenderness ratio of bending members and Slenderness the
+	// largest le/d of compression members, which are both limited to 50
+	RB, Slenderness float64
+}
+
+// Adjust finds the adjusted design values of a member of size s with grade v
+// for a combination c.  The member is braced l ft apart against buckling
+// about both axes and lateral torsional buckling.  Sawn timbers take the
+// table 4D values of the grade.
+func Adjust(v *model.DesignValues, s Size, l float64, c model.Case, method Method, service Service) (Adjusted, error) {
+	if s.B <= 0 || s.D <= 0 || l <= 0 {
+		return Adjusted{}, fmt.Errorf("members need a size and length")
+	}
+	if s.timber() && !v.Glulam {
+		var err error
+		if v, err = timberValues(v, s); err != nil {
+			return Adjusted{}, err
+		}
+	}
+	ctTension, ctOther, err := temperature(service)
+	if err != nil {
+		return Adjusted{}, err
+	}
+	cfb, cft, cfc := sizeFactor(v, s)
+	cmb, cmt, cmv, cmc, cme := 1., 1., 1., 1., 1.
+	if service.Wet {
+		cmb, cmt, cmv, cmc, cme = wetService(v, s, cfb, cfc)
+	}
+
+	// format conversion KF times phi of each value for LRFD
+	kb, kt, kv, kc, ks := 1., 1., 1., 1., 1.
+	time := LoadDuration(c)
+	a := Adjusted{CD: time}
+	if method == LRFD {
+		time = TimeEffect(c)
+		a.CD = 1
+		kb, kt, kv, kc, ks = 2.54*0.85, 2.70*0.80, 2.88*0.75, 2.40*0.90, 1.76*0.85
+	}
+
+	a.CM, a.Ct, a.CF, a.Cfu = cmb, ctOther, cfb, flatUse(v, s)
+	a.Emin = v.Emin * cme * ctTension * ks
+	a.Ft = factors{time * kt, cmt, ctTension, cft}.apply(v.Ft)
+	a.Fv = factors{time * kv, cmv, ctOther, 1}.apply(v.Fv)
+	a.FbzStar = factors{time * kb, cmb, ctOther, cfb}.apply(v.Fb)
+	a.Fby = a.FbzStar * a.Cfu
+
+	// beam stability only matters when the depth exceeds the breadth
+	lu := l * 12
+	a.CL = 1
+	a.FbE = math.Inf(1)
+	if s.D > s.B {
+		a.RB = math.Sqrt(beamLength(lu, s.D) * s.D / (s.B * s.B))
+		a.FbE = 1.20 * a.Emin / (a.RB * a.RB)
+		a.CL = stability(a.FbE/a.FbzStar, 0.95)
+	}
+	a.CV = 1
+	if v.Glulam {
+		a.CV = volumeFactor(s, l)
+	}
+	a.Fbz = a.FbzStar * math.Min(a.CL, a.CV)
+
+	// columns buckle about the weaker axis
+	fcStar := factors{time * kc, cmc, ctOther, cfc}.apply(v.Fc)
+	a.Slenderness = math.Max(lu/s.D, lu/s.B)
+	a.FcEz = 0.822 * a.Emin / math.Pow(lu/s.D, 2)
+	a.FcEy = 0.822 * a.Emin / math.Pow(lu/s.B, 2)
+	cp := 0.8
+	if v.Glulam {
+		cp = 0.9
+	}
+	a.CP = stability(math.Min(a.FcEz, a.FcEy)/fcStar, cp)
+	a.Fc = fcStar * a.CP
+	return a, nil
+}
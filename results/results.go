@@ -0,0 +1,296 @@
+// Package results reads the analysis output of a skyciv S3D.model.solve call
+// into go types and traces each skyciv member back to the continuous member
+// and segment it was exported from.
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/donniet/goframes/model"
+)
+
+// Component names used by skyciv for member forces
+const (
+	AxialForce     = "axial_force"
+	ShearForceY    = "shear_force_y"
+	ShearForceZ    = "shear_force_z"
+	Torsion        = "torsion"
+	BendingMomentY = "bending_moment_y"
+	BendingMomentZ = "bending_moment_z"
+)
+
+var Components = []string{AxialForce, ShearForceY, ShearForceZ, Torsion, BendingMomentY, BendingMomentZ}
+
+// Displacement of a node in translation units and radians
+type Displacement struct {
+	TX float64 `json:"TX"`
+	TY float64 `json:"TY"`
+	TZ float64 `json:"TZ"`
+	RX float64 `json:"RX"`
+	RY float64 `json:"RY"`
+	RZ float64 `json:"RZ"`
+}
+
+// Reaction at a support in force and moment units
+type Reaction struct {
+	Fx float64 `json:"Fx"`
+	Fy float64 `json:"Fy"`
+	Fz float64 `json:"Fz"`
+	Mx float64 `json:"Mx"`
+	My float64 `json:"My"`
+	Mz float64 `json:"Mz"`
+}
+
+// Station is a value at a position along a member given as a percent of its
+// length
+type Station struct {
+	Position float64
+	Value    float64
+}
+
+// Peak is the extreme values of a component along a member
+type Peak struct {
+	Min float64
+	Max float64
+}
+
+// AbsMax is the largest magnitude of the peak
+func (p Peak) AbsMax() float64 {
+	return math.Max(math.Abs(p.Min), math.Abs(p.Max))
+}
+
+// MemberResult holds the forces along a single skyciv member.  Member and Index
+// locate the segment within the continuous member it was exported from, and
+// are only set when the results are parsed with a model.
+type MemberResult struct {
+	Id     int
+	Member *model.ContinuousMember
+	Index  int
+
+	Forces map[string][]Station
+	Peaks  map[string]Peak
+}
+
+// Peak returns the extreme values of the component
+func (r *MemberResult) Peak(component string) Peak {
+	return r.Peaks[component]
+}
+
+// Case holds the results of a single load combination
+type Case struct {
+	Key           string
+	Name          string
+	Displacements map[int]Displacement
+	Reactions     map[int]Reaction
+	Members       map[int]*MemberResult
+}
+
+// ForMember returns the results of every segment of mem in order
+func (c *Case) ForMember(mem *model.ContinuousMember) (ret []*MemberResult) {
+	for _, r := range c.Members {
+		if r.Member == mem {
+			ret = append(ret, r)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Index < ret[j].Index })
+	return
+}
+
+// PeakForMember combines the peak of a component over every segment of mem
+func (c *Case) PeakForMember(mem *model.ContinuousMember, component string) (p Peak) {
+	for i, r := range c.ForMember(mem) {
+		q := r.Peak(component)
+		if i == 0 {
+			p = q
+			continue
+		}
+		p.Min = math.Min(p.Min, q.Min)
+		p.Max = math.Max(p.Max, q.Max)
+	}
+	return
+}
+
+type Results struct {
+	Cases []*Case
+}
+
+// Case finds the results of the load combination with the given name or nil
+func (r *Results) Case(name string) *Case {
+	for _, c := range r.Cases {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// raw mirrors the json skyciv returns for each load combination
+type raw struct {
+	Name               string                                   `json:"name"`
+	NodalDisplacements map[string]Displacement                  `json:"nodal_displacements"`
+	Reactions          map[string]Reaction                      `json:"reactions"`
+	MemberForces       map[string]map[string]map[string]float64 `json:"member_forces"`
+	MemberMinimums     map[string]map[string]float64            `json:"member_minimums"`
+	MemberMaximums     map[string]map[string]float64            `json:"member_maximums"`
+}
+
+// Parse reads the data of a solve response.  If m is not nil each member is
+// traced back to the continuous member it came from.
+func Parse(data []byte, m *model.Skyciv) (*Results, error) {
+	cases := make(map[string]*raw)
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("parsing skyciv results: %v", err)
+	}
+
+	var segments map[int]model.Segment
+	if m != nil {
+		segments = make(map[int]model.Segment)
+		for _, s := range m.ContinuousMembers.Segments() {
+			segments[s.Id] = s
+		}
+	}
+
+	keys := make([]string, 0, len(cases))
+	for k := range cases {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	res := &Results{}
+	for _, k := range keys {
+		c, err := parseCase(k, cases[k], segments)
+		if err != nil {
+			return nil, err
+		}
+		res.Cases = append(res.Cases, c)
+	}
+	return res, nil
+}
+
+// sortKeys orders numeric keys numerically and everything else after them
+func sortKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, aerr := strconv.Atoi(keys[i])
+		b, berr := strconv.Atoi(keys[j])
+		switch {
+		case aerr == nil && berr == nil:
+			return a < b
+		case aerr == nil:
+			return true
+		case berr == nil:
+			return false
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+func parseCase(key string, r *raw, segments map[int]model.Segment) (*Case, error) {
+	c := &Case{
+		Key:           key,
+		Name:          r.Name,
+		Displacements: make(map[int]Displacement),
+		Reactions:     make(map[int]Reaction),
+		Members:       make(map[int]*MemberResult),
+	}
+
+	for k, d := range r.NodalDisplacements {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: bad node id %q", key, k)
+		}
+		c.Displacements[id] = d
+	}
+	for k, d := range r.Reactions {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: bad node id %q", key, k)
+		}
+		c.Reactions[id] = d
+	}
+
+	member := func(k string) (*MemberResult, error) {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: bad member id %q", key, k)
+		}
+		if mr, ok := c.Members[id]; ok {
+			return mr, nil
+		}
+		mr := &MemberResult{
+			Id:     id,
+			Forces: make(map[string][]Station),
+			Peaks:  make(map[string]Peak),
+		}
+		if segments != nil {
+			s, ok := segments[id]
+			if !ok {
+				return nil, fmt.Errorf("case %s: member %d is not in the model", key, id)
+			}
+			mr.Member, mr.Index = s.Member, s.Index
+		}
+		c.Members[id] = mr
+		return mr, nil
+	}
+
+	for comp, byMember := range r.MemberForces {
+		for k, stations := range byMember {
+			mr, err := member(k)
+			if err != nil {
+				return nil, err
+			}
+			st := make([]Station, 0, len(stations))
+			for pos, v := range stations {
+				p, err := strconv.ParseFloat(pos, 64)
+				if err != nil {
+					return nil, fmt.Errorf("case %s: member %s has bad station %q", key, k, pos)
+				}
+				st = append(st, Station{Position: p, Value: v})
+			}
+			sort.Slice(st, func(i, j int) bool { return st[i].Position < st[j].Position })
+			mr.Forces[comp] = st
+			mr.Peaks[comp] = peakOf(st)
+		}
+	}
+
+	// prefer the peaks skyciv reports since they are not limited to stations
+	for comp, byMember := range r.MemberMinimums {
+		for k, v := range byMember {
+			mr, err := member(k)
+			if err != nil {
+				return nil, err
+			}
+			p := mr.Peaks[comp]
+			p.Min = v
+			mr.Peaks[comp] = p
+		}
+	}
+	for comp, byMember := range r.MemberMaximums {
+		for k, v := range byMember {
+			mr, err := member(k)
+			if err != nil {
+				return nil, err
+			}
+			p := mr.Peaks[comp]
+			p.Max = v
+			mr.Peaks[comp] = p
+		}
+	}
+
+	return c, nil
+}
+
+func peakOf(st []Station) (p Peak) {
+	for i, s := range st {
+		if i == 0 || s.Value < p.Min {
+			p.Min = s.Value
+		}
+		if i == 0 || s.Value > p.Max {
+			p.Max = s.Value
+		}
+	}
+	return
+}
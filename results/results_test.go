@@ -0,0 +1,67 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/donniet/goframes/model"
+)
+
+const solved = `{
+	"0": {
+		"name": "ULS: 1. 1.4D",
+		"reactions": {"1": {"Fx": 0, "Fy": 2.5, "Fz": 0, "Mx": 0, "My": 0, "Mz": 1.25}},
+		"nodal_displacements": {"3": {"TX": 0.1, "TY": -0.2}},
+		"member_forces": {
+			"bending_moment_z": {
+				"1": {"0.0": -1.25, "50.0": 0.5, "100.0": 0.25},
+				"2": {"0.0": 0.25, "100.0": 0}
+			}
+		},
+		"member_maximums": {"bending_moment_z": {"1": 0.75}}
+	},
+	"1": {"name": "ULS: 5. 0.9D + W"}
+}`
+
+func TestParse(T *testing.T) {
+	m := model.NewModel(nil)
+	mat := m.NewMaterial("test")
+	sec := m.NewSectionFromLibrary(mat, "test")
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 0, 10, 0)
+	if _, err := mem.Split(5); err != nil {
+		T.Fatal(err)
+	}
+
+	res, err := Parse([]byte(solved), m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if len(res.Cases) != 2 || res.Cases[1].Name != "ULS: 5. 0.9D + W" {
+		T.Fatalf("cases parsed out of order %v", res.Cases)
+	}
+
+	c := res.Case("ULS: 1. 1.4D")
+	if r := c.Reactions[1]; r.Fy != 2.5 || r.Mz != 1.25 {
+		T.Errorf("reaction %+v", r)
+	}
+	if d := c.Displacements[3]; d.TY != -0.2 {
+		T.Errorf("displacement %+v", d)
+	}
+
+	segs := c.ForMember(mem)
+	if len(segs) != 2 || segs[0].Index != 0 || segs[1].Index != 1 {
+		T.Fatalf("members not traced back to continuous member %v", segs)
+	}
+	if st := segs[0].Forces[BendingMomentZ]; len(st) != 3 || st[1].Position != 50 {
+		T.Errorf("stations %v", st)
+	}
+	if p := c.PeakForMember(mem, BendingMomentZ); p.Min != -1.25 || p.Max != 0.75 {
+		T.Errorf("peak %+v", p)
+	}
+}
+
+func TestParseUnknownMember(T *testing.T) {
+	m := model.NewModel(nil)
+	if _, err := Parse([]byte(solved), m); err == nil {
+		T.Errorf("members missing from the model were not reported")
+	}
+}
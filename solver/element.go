@@ -0,0 +1,324 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/donniet/goframes/model"
+)
+
+// element is a single segment of a continuous member with its stiffness in
+// kip and ft
+type element struct {
+	segment    model.Segment
+	a, b       int // node indices
+	offA, offB model.Vector
+	start      float64 // distance from the begin of the continuous member
+	length     float64
+	rot        [3]model.Vector // local x, y and z axes in global coordinates
+	k          [2 * dof][2 * dof]float64
+	weight     float64 // kip/ft
+
+	// released dofs and the column of k used to condense each one out
+	released []int
+	condense [][2 * dof]float64
+}
+
+func newElement(seg model.Segment, props Properties, mat *model.Material) (*element, error) {
+	// the flexible part of the member runs between the offset ends
+	endA := seg.A.ToVector().Sum(seg.OffsetA)
+	endB := seg.B.ToVector().Sum(seg.OffsetB)
+
+	L := endB.Diff(endA).Length()
+	if L <= 0 {
+		return nil, fmt.Errorf("member %d has zero length", seg.Id)
+	}
+
+	e := &element{segment: seg, length: L, offA: seg.OffsetA, offB: seg.OffsetB}
+	e.rot[0], e.rot[1], e.rot[2] = model.LocalAxes(endA, endB, seg.Member.RotationAngle)
+
+	E := mat.ElasticityModulus * sqInPerSqFt
+	G := E / (2 * (1 + mat.PoissonsRatio))
+	A := props.Area / sqInPerSqFt
+	Iy := props.Iy / quadInPerQuad
+	Iz := props.Iz / quadInPerQuad
+	J := props.J / quadInPerQuad
+
+	e.weight = mat.Density / lbPerKip * A
+
+	L2, L3 := L*L, L*L*L
+	k := &e.k
+
+	k[0][0], k[0][6] = E*A/L, -E*A/L
+	k[6][6] = E * A / L
+
+	k[1][1], k[1][5], k[1][7], k[1][11] = 12*E*Iz/L3, 6*E*Iz/L2, -12*E*Iz/L3, 6*E*Iz/L2
+	k[5][5], k[5][7], k[5][11] = 4*E*Iz/L, -6*E*Iz/L2, 2*E*Iz/L
+	k[7][7], k[7][11] = 12*E*Iz/L3, -6*E*Iz/L2
+	k[11][11] = 4 * E * Iz / L
+
+	k[2][2], k[2][4], k[2][8], k[2][10] = 12*E*Iy/L3, -6*E*Iy/L2, -12*E*Iy/L3, -6*E*Iy/L2
+	k[4][4], k[4][8], k[4][10] = 4*E*Iy/L, 6*E*Iy/L2, 2*E*Iy/L
+	k[8][8], k[8][10] = 12*E*Iy/L3, 6*E*Iy/L2
+	k[10][10] = 4 * E * Iy / L
+
+	k[3][3], k[3][9] = G*J/L, -G*J/L
+	k[9][9] = G * J / L
+
+	for i := 0; i < 2*dof; i++ {
+		for j := 0; j < i; j++ {
+			k[i][j] = k[j][i]
+		}
+	}
+
+	if err := e.release(seg.FixityA, 0); err != nil {
+		return nil, fmt.Errorf("member %d: %v", seg.Id, err)
+	}
+	if err := e.release(seg.FixityB, dof); err != nil {
+		return nil, fmt.Errorf("member %d: %v", seg.Id, err)
+	}
+
+	return e, nil
+}
+
+// release statically condenses the dofs released by a fixity code out of the
+// stiffness matrix, leaving zero force in them
+func (e *element) release(fixity string, first int) error {
+	if len(fixity) != dof {
+		return fmt.Errorf("fixity code %q is not %d characters", fixity, dof)
+	}
+
+	k := &e.k
+	for i, c := range fixity {
+		switch c {
+		case 'F':
+			continue
+		case 'R':
+		default:
+			return fmt.Errorf("unknown fixity %q in %q", c, fixity)
+		}
+
+		j := first + i
+		var col [2 * dof]float64
+		if d := k[j][j]; d > 0 {
+			for p := 0; p < 2*dof; p++ {
+				col[p] = k[p][j] / d
+			}
+		}
+		row := k[j]
+		for p := 0; p < 2*dof; p++ {
+			for q := 0; q < 2*dof; q++ {
+				k[p][q] -= col[p] * row[q]
+			}
+		}
+		for p := 0; p < 2*dof; p++ {
+			k[p][j], k[j][p] = 0, 0
+		}
+
+		e.released = append(e.released, j)
+		e.condense = append(e.condense, col)
+	}
+	return nil
+}
+
+// condenseLoad removes the released dofs from a vector of fixed end forces the
+// same way release did from the stiffness matrix
+func (e *element) condenseLoad(f [2 * dof]float64) [2 * dof]float64 {
+	for i, j := range e.released {
+		col := e.condense[i]
+		r := f[j]
+		for p := 0; p < 2*dof; p++ {
+			f[p] -= col[p] * r
+		}
+		f[j] = 0
+	}
+	return f
+}
+
+// dofs lists the global degrees of freedom of both ends
+func (e *element) dofs() (ret [2 * dof]int) {
+	for j := 0; j < dof; j++ {
+		ret[j] = dof*e.a + j
+		ret[dof+j] = dof*e.b + j
+	}
+	return
+}
+
+// toLocal rotates a global vector into member axes
+func (e *element) toLocal(v model.Vector) model.Vector {
+	return model.Vector{X: e.rot[0].Dot(v), Y: e.rot[1].Dot(v), Z: e.rot[2].Dot(v)}
+}
+
+// toGlobal rotates a member axes vector into global axes
+func (e *element) toGlobal(v model.Vector) model.Vector {
+	return e.rot[0].Scale(v.X).Sum(e.rot[1].Scale(v.Y)).Sum(e.rot[2].Scale(v.Z))
+}
+
+// rotate applies the member rotation to each triple of f, either into local
+// or global axes
+func (e *element) rotate(f [2 * dof]float64, local bool) (ret [2 * dof]float64) {
+	for i := 0; i < 2*dof; i += 3 {
+		v := model.Vector{X: f[i], Y: f[i+1], Z: f[i+2]}
+		if local {
+			v = e.toLocal(v)
+		} else {
+			v = e.toGlobal(v)
+		}
+		ret[i], ret[i+1], ret[i+2] = v.X, v.Y, v.Z
+	}
+	return
+}
+
+// offset returns the rigid link matrix taking node displacements to the
+// displacements of the offset member ends.  Its transpose takes end forces
+// back to the nodes.
+func (e *element) offset() (o [2 * dof][2 * dof]float64) {
+	for i := 0; i < 2*dof; i++ {
+		o[i][i] = 1
+	}
+	// t_end = t_node + theta x offset
+	for end, off := range []model.Vector{e.offA, e.offB} {
+		b := end * dof
+		o[b+0][b+4], o[b+0][b+5] = off.Z, -off.Y
+		o[b+1][b+3], o[b+1][b+5] = -off.Z, off.X
+		o[b+2][b+3], o[b+2][b+4] = off.Y, -off.X
+	}
+	return
+}
+
+func (e *element) hasOffset() bool {
+	return e.offA != (model.Vector{}) || e.offB != (model.Vector{})
+}
+
+// toNodes moves global forces at the member ends to the nodes
+func (e *element) toNodes(f [2 * dof]float64) (ret [2 * dof]float64) {
+	if !e.hasOffset() {
+		return f
+	}
+	o := e.offset()
+	for i := 0; i < 2*dof; i++ {
+		for j := 0; j < 2*dof; j++ {
+			ret[i] += o[j][i] * f[j]
+		}
+	}
+	return
+}
+
+// toEnds moves global node displacements to the member ends
+func (e *element) toEnds(u [2 * dof]float64) (ret [2 * dof]float64) {
+	if !e.hasOffset() {
+		return u
+	}
+	o := e.offset()
+	for i := 0; i < 2*dof; i++ {
+		for j := 0; j < 2*dof; j++ {
+			ret[i] += o[i][j] * u[j]
+		}
+	}
+	return
+}
+
+func (e *element) globalStiffness() (kg [2 * dof][2 * dof]float64) {
+	kg = e.endStiffness()
+	if !e.hasOffset() {
+		return
+	}
+
+	// kg = O^T kg O
+	o := e.offset()
+	var ko [2 * dof][2 * dof]float64
+	for i := 0; i < 2*dof; i++ {
+		for j := 0; j < 2*dof; j++ {
+			for p := 0; p < 2*dof; p++ {
+				ko[i][j] += kg[i][p] * o[p][j]
+			}
+		}
+	}
+	kg = [2 * dof][2 * dof]float64{}
+	for i := 0; i < 2*dof; i++ {
+		for j := 0; j < 2*dof; j++ {
+			for p := 0; p < 2*dof; p++ {
+				kg[i][j] += o[p][i] * ko[p][j]
+			}
+		}
+	}
+	return
+}
+
+// endStiffness is the stiffness of the member ends in global axes
+func (e *element) endStiffness() (kg [2 * dof][2 * dof]float64) {
+	// kg = T^T k T, done one 3x3 block at a time
+	var t [3][3]float64
+	for i := 0; i < 3; i++ {
+		t[i] = [3]float64{e.rot[i].X, e.rot[i].Y, e.rot[i].Z}
+	}
+
+	for bi := 0; bi < 2*dof; bi += 3 {
+		for bj := 0; bj < 2*dof; bj += 3 {
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					v := 0.
+					for p := 0; p < 3; p++ {
+						for q := 0; q < 3; q++ {
+							v += t[p][i] * e.k[bi+p][bj+q] * t[q][j]
+						}
+					}
+					kg[bi+i][bj+j] = v
+				}
+			}
+		}
+	}
+	return
+}
+
+// endForces finds the forces acting on the member ends in local and global
+// axes given the global displacements and the local fixed end forces of any
+// loads along the member
+func (e *element) endForces(u []float64, fixed [2 * dof]float64) (local, global [2 * dof]float64) {
+	var ug [2 * dof]float64
+	for i, g := range e.dofs() {
+		ug[i] = u[g]
+	}
+	ul := e.rotate(e.toEnds(ug), true)
+
+	for i := 0; i < 2*dof; i++ {
+		local[i] = fixed[i]
+		for j := 0; j < 2*dof; j++ {
+			local[i] += e.k[i][j] * ul[j]
+		}
+	}
+	global = e.toNodes(e.rotate(local, false))
+	return
+}
+
+// pointFixedEnd finds the fixed end forces of a force p, given in local axes,
+// a from the A end of the member
+func (e *element) pointFixedEnd(p model.Vector, a float64) (f [2 * dof]float64) {
+	L := e.length
+	b := L - a
+	L2, L3 := L*L, L*L*L
+	f[0], f[6] = -p.X*b/L, -p.X*a/L
+	f[1], f[7] = -p.Y*b*b*(3*a+b)/L3, -p.Y*a*a*(a+3*b)/L3
+	f[5], f[11] = -p.Y*a*b*b/L2, p.Y*a*a*b/L2
+	f[2], f[8] = -p.Z*b*b*(3*a+b)/L3, -p.Z*a*a*(a+3*b)/L3
+	f[4], f[10] = p.Z*a*b*b/L2, -p.Z*a*a*b/L2
+	return
+}
+
+// along converts a distance from the begin of the continuous member into a
+// distance from the A end of the flexible part of this element
+func (e *element) along(distance float64) float64 {
+	nodeLength := model.Distance(e.segment.A, e.segment.B)
+	return (distance - e.start) / nodeLength * e.length
+}
+
+// uniformFixedEnd finds the fixed end forces of a uniform load w, given in
+// local axes per unit length, along the whole member
+func (e *element) uniformFixedEnd(w model.Vector) (f [2 * dof]float64) {
+	L := e.length
+	f[0], f[6] = -w.X*L/2, -w.X*L/2
+	f[1], f[7] = -w.Y*L/2, -w.Y*L/2
+	f[5], f[11] = -w.Y*L*L/12, w.Y*L*L/12
+	f[2], f[8] = -w.Z*L/2, -w.Z*L/2
+	f[4], f[10] = w.Z*L*L/12, -w.Z*L*L/12
+	return
+}
@@ -0,0 +1,201 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/donniet/goframes/model"
+)
+
+// loadVector holds every load of a single load group
+type loadVector struct {
+	nodal      []float64 // loads applied directly to nodes
+	equivalent []float64 // nodal equivalents of the member loads
+	fixed      map[*element][2 * dof]float64
+}
+
+func (s *system) newLoadVector() *loadVector {
+	return &loadVector{
+		nodal:      make([]float64, dof*len(s.nodes)),
+		equivalent: make([]float64, dof*len(s.nodes)),
+		fixed:      make(map[*element][2 * dof]float64),
+	}
+}
+
+// addFixed adds the local fixed end forces of a member load along e
+func (lv *loadVector) addFixed(e *element, f [2 * dof]float64) {
+	f = e.condenseLoad(f)
+
+	prev := lv.fixed[e]
+	for i := range prev {
+		prev[i] += f[i]
+	}
+	lv.fixed[e] = prev
+
+	global := e.toNodes(e.rotate(f, false))
+	for i, g := range e.dofs() {
+		lv.equivalent[g] -= global[i]
+	}
+}
+
+// addNodal adds a global vector to the node with the given id, starting at
+// dof first: 0 for forces and 3 for moments
+func (s *system) addNodal(lv *loadVector, id int, first int, f model.Vector) error {
+	i, ok := s.index[id]
+	if !ok {
+		return fmt.Errorf("node %d is not connected to any member", id)
+	}
+	lv.nodal[dof*i+first] += f.X
+	lv.nodal[dof*i+first+1] += f.Y
+	lv.nodal[dof*i+first+2] += f.Z
+	return nil
+}
+
+// elementAt finds the element of mem containing the point distance from its
+// begin
+func (s *system) elementAt(mem *model.ContinuousMember, distance float64) (*element, error) {
+	els := s.members[mem]
+	for _, e := range els {
+		if distance <= e.start+model.Distance(e.segment.A, e.segment.B) {
+			return e, nil
+		}
+	}
+	if len(els) > 0 && distance-mem.Length() < 1e-9 {
+		return els[len(els)-1], nil
+	}
+	return nil, fmt.Errorf("distance %f is not along the member", distance)
+}
+
+func (s *system) pointLoad(lv *loadVector, pl *model.PointLoad) error {
+	if pl.Node != nil {
+		if err := s.addNodal(lv, pl.Node.Id, 0, pl.Mag); err != nil {
+			return fmt.Errorf("point load %d: %v", pl.Id, err)
+		}
+		return nil
+	}
+
+	e, err := s.elementAt(pl.Member, pl.Distance)
+	if err != nil {
+		return fmt.Errorf("point load %d: %v", pl.Id, err)
+	}
+	lv.addFixed(e, e.pointFixedEnd(e.toLocal(pl.Mag), e.along(pl.Distance)))
+	return nil
+}
+
+// gauss are the points and weights of 3 point gauss-legendre quadrature on
+// [0, 1], exact for the quartic fixed end forces of a linearly varying load
+var gauss = [3][2]float64{
+	{0.5 - math.Sqrt(0.15), 5. / 18.},
+	{0.5, 8. / 18.},
+	{0.5 + math.Sqrt(0.15), 5. / 18.},
+}
+
+// distributedLoad integrates the point load fixed end forces over the part of
+// each element the load covers
+func (s *system) distributedLoad(lv *loadVector, dl *model.DistributedLoad) error {
+	els := s.members[dl.Member]
+	if len(els) == 0 {
+		return fmt.Errorf("distributed load %d is on a member that is not in the model", dl.Id)
+	}
+
+	for _, e := range els {
+		nodeLength := model.Distance(e.segment.A, e.segment.B)
+		a, b := math.Max(dl.Start, e.start), math.Min(dl.End, e.start+nodeLength)
+		if a >= b {
+			continue
+		}
+		// integrate over the flexible length
+		scale := e.length / nodeLength
+
+		var f [2 * dof]float64
+		for _, g := range gauss {
+			d := a + g[0]*(b-a)
+			w := e.toLocal(dl.MagAt(d)).Scale(g[1] * (b - a) * scale)
+			p := e.pointFixedEnd(w, e.along(d))
+			for i := range f {
+				f[i] += p[i]
+			}
+		}
+		lv.addFixed(e, f)
+	}
+	return nil
+}
+
+// loadGroups builds a load vector for every load group in the model
+func (s *system) loadGroups() (map[string]*loadVector, error) {
+	groups := make(map[string]*loadVector)
+	group := func(name string) *loadVector {
+		lv, ok := groups[name]
+		if !ok {
+			lv = s.newLoadVector()
+			groups[name] = lv
+		}
+		return lv
+	}
+
+	for _, sw := range s.m.SelfWeight {
+		lv := group(sw.LoadGroup)
+		g := model.Vector{X: sw.X, Y: sw.Y, Z: sw.Z}
+		for _, e := range s.elements {
+			lv.addFixed(e, e.uniformFixedEnd(e.toLocal(g.Scale(e.weight))))
+		}
+	}
+
+	for _, al := range s.m.AreaLoads {
+		if err := s.areaLoad(group(al.LoadGroup), al); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pl := range s.m.PointLoads {
+		if err := s.pointLoad(group(pl.LoadGroup), pl); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mo := range s.m.Moments {
+		if err := s.addNodal(group(mo.LoadGroup), mo.Node.Id, 3, mo.Mag); err != nil {
+			return nil, fmt.Errorf("moment %d: %v", mo.Id, err)
+		}
+	}
+
+	for _, dl := range s.m.DistributedLoads {
+		if err := s.distributedLoad(group(dl.LoadGroup), dl); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// areaLoad lumps an area load onto its corner nodes by their tributary area
+func (s *system) areaLoad(lv *loadVector, al *model.AreaLoad) error {
+	var dir model.Vector
+	switch al.Direction {
+	case "X":
+		dir.X = 1
+	case "Y":
+		dir.Y = 1
+	case "Z":
+		dir.Z = 1
+	default:
+		return fmt.Errorf("area load %d has unsupported direction %q", al.Id, al.Direction)
+	}
+
+	pts := make([]model.Vector, len(al.Nodes))
+	for i, id := range al.Nodes {
+		n, ok := s.m.Nodes[id]
+		if !ok {
+			return fmt.Errorf("area load %d references missing node %d", al.Id, id)
+		}
+		pts[i] = n.ToVector()
+	}
+	share := model.Tributary(pts)
+
+	for i, id := range al.Nodes {
+		if err := s.addNodal(lv, id, 0, dir.Scale(al.Mag*share[i])); err != nil {
+			return fmt.Errorf("area load %d: %v", al.Id, err)
+		}
+	}
+	return nil
+}
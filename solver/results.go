@@ -0,0 +1,141 @@
+package solver
+
+import (
+	"sort"
+
+	"github.com/donniet/goframes/model"
+)
+
+// Displacement of a node in ft and radians
+type Displacement struct {
+	TX, TY, TZ float64
+	RX, RY, RZ float64
+}
+
+// Reaction at a support in kip and kip-ft
+type Reaction struct {
+	FX, FY, FZ float64
+	MX, MY, MZ float64
+}
+
+// EndForces act on the end of a member in its local axes in kip and kip-ft
+type EndForces struct {
+	Axial   float64
+	ShearY  float64
+	ShearZ  float64
+	Torsion float64
+	MomentY float64
+	MomentZ float64
+}
+
+// MemberForces are the end forces of a single segment of a continuous member
+type MemberForces struct {
+	Segment model.Segment
+	A, B    EndForces
+}
+
+// CaseResult holds the results of a single load combination.  Displacements
+// and reactions are keyed by node id and members by segment id.
+type CaseResult struct {
+	Name          string
+	Displacements map[int]Displacement
+	Reactions     map[int]Reaction
+	Members       map[int]*MemberForces
+}
+
+type Results struct {
+	Cases []*CaseResult
+}
+
+// Case finds the result with the given name or nil
+func (r *Results) Case(name string) *CaseResult {
+	for _, c := range r.Cases {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func displacementFrom(d [dof]float64) Displacement {
+	return Displacement{d[0], d[1], d[2], d[3], d[4], d[5]}
+}
+
+func reactionFrom(f [dof]float64) Reaction {
+	return Reaction{f[0], f[1], f[2], f[3], f[4], f[5]}
+}
+
+func endForcesFrom(f []float64) EndForces {
+	return EndForces{f[0], f[1], f[2], f[3], f[4], f[5]}
+}
+
+func (d Displacement) add(s float64, o Displacement) Displacement {
+	return Displacement{
+		d.TX + s*o.TX, d.TY + s*o.TY, d.TZ + s*o.TZ,
+		d.RX + s*o.RX, d.RY + s*o.RY, d.RZ + s*o.RZ,
+	}
+}
+
+func (r Reaction) add(s float64, o Reaction) Reaction {
+	return Reaction{
+		r.FX + s*o.FX, r.FY + s*o.FY, r.FZ + s*o.FZ,
+		r.MX + s*o.MX, r.MY + s*o.MY, r.MZ + s*o.MZ,
+	}
+}
+
+func (f EndForces) add(s float64, o EndForces) EndForces {
+	return EndForces{
+		f.Axial + s*o.Axial, f.ShearY + s*o.ShearY, f.ShearZ + s*o.ShearZ,
+		f.Torsion + s*o.Torsion, f.MomentY + s*o.MomentY, f.MomentZ + s*o.MomentZ,
+	}
+}
+
+// combine sums the load group results into the model's load combinations
+func combine(m *model.Skyciv, groups map[string]*CaseResult) *Results {
+	res := &Results{}
+
+	if len(m.LoadCombinations.Cases) == 0 {
+		names := make([]string, 0, len(groups))
+		for name := range groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			res.Cases = append(res.Cases, groups[name])
+		}
+		return res
+	}
+
+	for _, c := range m.LoadCombinations.Cases {
+		cr := &CaseResult{
+			Name:          c.Name,
+			Displacements: make(map[int]Displacement),
+			Reactions:     make(map[int]Reaction),
+			Members:       make(map[int]*MemberForces),
+		}
+
+		for name, f := range m.LoadCombinations.Mapping.Factors(c) {
+			g, ok := groups[name]
+			if !ok || f == 0 {
+				continue
+			}
+			for id, d := range g.Displacements {
+				cr.Displacements[id] = cr.Displacements[id].add(f, d)
+			}
+			for id, r := range g.Reactions {
+				cr.Reactions[id] = cr.Reactions[id].add(f, r)
+			}
+			for id, mf := range g.Members {
+				sum, ok := cr.Members[id]
+				if !ok {
+					sum = &MemberForces{Segment: mf.Segment}
+					cr.Members[id] = sum
+				}
+				sum.A = sum.A.add(f, mf.A)
+				sum.B = sum.B.add(f, mf.B)
+			}
+		}
+		res.Cases = append(res.Cases, cr)
+	}
+	return res
+}
@@ -0,0 +1,350 @@
+// Package solver is a linear elastic direct stiffness solver for the 3D frames
+// built in the model package.  It lets frame geometry be checked offline
+// without uploading every variant to skyciv.
+//
+// The solver works in the imperial units used by materials.json: lengths in
+// ft, section dimensions in in, elasticity moduli in ksi, densities in lb/ft^3,
+// forces in kip and pressures in ksf.  Results are reported in kip, kip-ft, ft
+// and radians.
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/donniet/goframes/model"
+	"github.com/donniet/goframes/units"
+)
+
+const (
+	dof = 6
+
+	sqInPerSqFt   = 144.
+	quadInPerQuad = 20736. // in^4 per ft^4
+	lbPerKip      = 1000.
+)
+
+var (
+	ErrUnstable       = errors.New("structure is unstable")
+	ErrMissingSection = errors.New("section properties are not defined")
+	ErrPlates         = errors.New("plates are not supported, solve the model with skyciv")
+	ErrUnits          = errors.New("model is not in imperial units")
+	ErrDirections     = errors.New("one way supports are not supported, solve the model with skyciv")
+)
+
+// DOFNames are the names of the six degrees of freedom at each node
+var DOFNames = [dof]string{"TX", "TY", "TZ", "RX", "RY", "RZ"}
+
+// Properties are the geometric properties of a section in section length
+// units (in^2 and in^4)
+type Properties struct {
+	Area float64
+	Iy   float64
+	Iz   float64
+	J    float64
+}
+
+// SectionProperties reads the properties stored on the section itself.  It is
+// the default Options.Properties.
+func SectionProperties(sec *model.Section) (Properties, error) {
+	if sec.Area <= 0 || sec.Iy <= 0 || sec.Iz <= 0 || sec.J <= 0 {
+		return Properties{}, fmt.Errorf("%w: section %d", ErrMissingSection, sec.Id)
+	}
+	return Properties{Area: sec.Area, Iy: sec.Iy, Iz: sec.Iz, J: sec.J}, nil
+}
+
+type Options struct {
+	// Properties looks up the geometric properties of a section.  Library
+	// sections only carry a skyciv path so callers will usually need to supply
+	// this.  Defaults to SectionProperties.
+	Properties func(*model.Section) (Properties, error)
+}
+
+// Solve analyzes m with the default options
+func Solve(m *model.Skyciv) (*Results, error) {
+	return SolveWithOptions(m, Options{})
+}
+
+// SolveWithOptions analyzes every load group in m and combines them into the
+// cases of m.LoadCombinations.  If there are no combinations each load group is
+// reported as its own case.
+func SolveWithOptions(m *model.Skyciv, opts Options) (*Results, error) {
+	if opts.Properties == nil {
+		opts.Properties = SectionProperties
+	}
+	if u := m.Settings.Units; u != nil {
+		if c, err := u.To(units.Imperial); err != nil || !c.Identity() {
+			return nil, ErrUnits
+		}
+	}
+
+	s, err := newSystem(m, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.loadGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.factor(); err != nil {
+		return nil, err
+	}
+
+	solved := make(map[string]*CaseResult)
+	for name, lv := range groups {
+		solved[name] = s.solve(name, lv)
+	}
+
+	return combine(m, solved), nil
+}
+
+// system is the assembled stiffness matrix of a model
+type system struct {
+	m        *model.Skyciv
+	elements []*element
+	members  map[*model.ContinuousMember][]*element
+	index    map[int]int // node id to node index
+	nodes    []*model.Node
+	free     []int // global dof to free dof or -1 if restrained
+	nfree    int
+	spring   []float64 // support spring stiffness of each global dof
+	k        []float64 // free dof stiffness, factored in place by factor
+}
+
+func newSystem(m *model.Skyciv, opts Options) (*system, error) {
+	if len(m.Plates) > 0 {
+		return nil, ErrPlates
+	}
+
+	s := &system{
+		m:       m,
+		members: make(map[*model.ContinuousMember][]*element),
+		index:   make(map[int]int),
+	}
+
+	for _, seg := range m.ContinuousMembers.Segments() {
+		sec := seg.Member.Section()
+		mat := m.Materials.ById(sec.MaterialId)
+		if mat == nil {
+			return nil, fmt.Errorf("section %d references missing material %d", sec.Id, sec.MaterialId)
+		}
+		props, err := opts.Properties(sec)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := newElement(seg, props, mat)
+		if err != nil {
+			return nil, err
+		}
+		e.a = s.addNode(seg.A)
+		e.b = s.addNode(seg.B)
+		if prev := s.members[seg.Member]; len(prev) > 0 {
+			last := prev[len(prev)-1]
+			e.start = last.start + model.Distance(last.segment.A, last.segment.B)
+		}
+		s.elements = append(s.elements, e)
+		s.members[seg.Member] = append(s.members[seg.Member], e)
+	}
+
+	if len(s.elements) == 0 {
+		return nil, fmt.Errorf("model has no members")
+	}
+
+	s.free = make([]int, dof*len(s.nodes))
+	s.spring = make([]float64, dof*len(s.nodes))
+	for i, n := range s.nodes {
+		code := ""
+		sup := n.Support()
+		if sup != nil {
+			code = sup.RestraintCode
+			if strings.Trim(sup.DirectionCode, "B") != "" {
+				return nil, ErrDirections
+			}
+		}
+		for j := 0; j < dof; j++ {
+			g := dof*i + j
+			if j < len(code) && code[j] == 'F' {
+				s.free[g] = -1
+				continue
+			}
+			if j < len(code) && code[j] == 'S' {
+				s.spring[g] = sup.Spring(j)
+			}
+			s.free[g] = s.nfree
+			s.nfree++
+		}
+	}
+
+	s.k = make([]float64, s.nfree*s.nfree)
+	for g, k := range s.spring {
+		if f := s.free[g]; f >= 0 {
+			s.k[f*s.nfree+f] += k
+		}
+	}
+	for _, e := range s.elements {
+		kg := e.globalStiffness()
+		dofs := e.dofs()
+		for i, gi := range dofs {
+			fi := s.free[gi]
+			if fi < 0 {
+				continue
+			}
+			for j, gj := range dofs {
+				fj := s.free[gj]
+				if fj < 0 {
+					continue
+				}
+				s.k[fi*s.nfree+fj] += kg[i][j]
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *system) addNode(n *model.Node) int {
+	if i, ok := s.index[n.Id]; ok {
+		return i
+	}
+	s.index[n.Id] = len(s.nodes)
+	s.nodes = append(s.nodes, n)
+	return len(s.nodes) - 1
+}
+
+// dofName describes a global dof for error messages
+func (s *system) dofName(g int) string {
+	return fmt.Sprintf("node %d %s", s.nodes[g/dof].Id, DOFNames[g%dof])
+}
+
+// factor replaces k with its cholesky factor.  A non-positive pivot means the
+// structure is a mechanism in that degree of freedom.
+func (s *system) factor() error {
+	n := s.nfree
+	k := s.k
+
+	// scale the pivot tolerance to the stiffest dof
+	maxDiag := 0.
+	for i := 0; i < n; i++ {
+		maxDiag = math.Max(maxDiag, k[i*n+i])
+	}
+	tol := maxDiag * 1e-12
+
+	for j := 0; j < n; j++ {
+		d := k[j*n+j]
+		for p := 0; p < j; p++ {
+			d -= k[j*n+p] * k[j*n+p]
+		}
+		if d <= tol {
+			return fmt.Errorf("%w: %s", ErrUnstable, s.dofName(s.globalDof(j)))
+		}
+		d = math.Sqrt(d)
+		k[j*n+j] = d
+
+		for i := j + 1; i < n; i++ {
+			v := k[i*n+j]
+			for p := 0; p < j; p++ {
+				v -= k[i*n+p] * k[j*n+p]
+			}
+			k[i*n+j] = v / d
+		}
+	}
+	return nil
+}
+
+func (s *system) globalDof(f int) int {
+	for g, v := range s.free {
+		if v == f {
+			return g
+		}
+	}
+	return -1
+}
+
+// backSubstitute solves L L^T x = b with the factored matrix
+func (s *system) backSubstitute(b []float64) []float64 {
+	n := s.nfree
+	k := s.k
+	x := make([]float64, n)
+	copy(x, b)
+
+	for i := 0; i < n; i++ {
+		for p := 0; p < i; p++ {
+			x[i] -= k[i*n+p] * x[p]
+		}
+		x[i] /= k[i*n+i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for p := i + 1; p < n; p++ {
+			x[i] -= k[p*n+i] * x[p]
+		}
+		x[i] /= k[i*n+i]
+	}
+	return x
+}
+
+// solve finds the displacements, reactions and member forces for a single
+// load group
+func (s *system) solve(name string, lv *loadVector) *CaseResult {
+	b := make([]float64, s.nfree)
+	for g, f := range s.free {
+		if f >= 0 {
+			b[f] = lv.nodal[g] + lv.equivalent[g]
+		}
+	}
+	x := s.backSubstitute(b)
+
+	u := make([]float64, dof*len(s.nodes))
+	for g, f := range s.free {
+		if f >= 0 {
+			u[g] = x[f]
+		}
+	}
+
+	r := &CaseResult{
+		Name:          name,
+		Displacements: make(map[int]Displacement),
+		Reactions:     make(map[int]Reaction),
+		Members:       make(map[int]*MemberForces),
+	}
+
+	for i, n := range s.nodes {
+		var d [dof]float64
+		copy(d[:], u[dof*i:])
+		r.Displacements[n.Id] = displacementFrom(d)
+	}
+
+	// sum the global end forces at each node to find the reactions
+	residual := make([]float64, dof*len(s.nodes))
+	for _, e := range s.elements {
+		local, global := e.endForces(u, lv.fixed[e])
+		for i, g := range e.dofs() {
+			residual[g] += global[i]
+		}
+		r.Members[e.segment.Id] = &MemberForces{
+			Segment: e.segment,
+			A:       endForcesFrom(local[:dof]),
+			B:       endForcesFrom(local[dof:]),
+		}
+	}
+
+	for i, n := range s.nodes {
+		if n.Support() == nil {
+			continue
+		}
+		var f [dof]float64
+		for j := 0; j < dof; j++ {
+			g := dof*i + j
+			if s.free[g] < 0 || s.spring[g] != 0 {
+				f[j] = residual[g] - lv.nodal[g]
+			}
+		}
+		r.Reactions[n.Id] = reactionFrom(f)
+	}
+
+	return r
+}
@@ -0,0 +1,255 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/donniet/goframes/model"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-6*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// unitModel has a material and section that make EA, EIz and the self weight
+// easy to compute by hand: E = 144000 ksf, A = 1 ft^2, Iz = 1 ft^4, Iy = 2 ft^4
+// and a weight of 1 kip/ft
+func unitModel() (*model.Skyciv, *model.Section) {
+	m := model.NewModel(nil)
+	mat := m.NewMaterial("unit")
+	mat.ElasticityModulus = 1000
+	mat.Density = 1000
+	mat.PoissonsRatio = 0.25
+	sec := m.NewSectionFromLibrary(mat, "unit")
+	sec.Area = 144
+	sec.Iz = 20736
+	sec.Iy = 2 * 20736
+	sec.J = 20736
+	return m, sec
+}
+
+func TestCantileverSelfWeight(T *testing.T) {
+	m, sec := unitModel()
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0)
+	mem.Begin().FixedSupport()
+	m.NewSelfWeight()
+
+	res, err := Solve(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	c := res.Case("SW1")
+	if c == nil {
+		T.Fatalf("no SW1 case in %v", res.Cases)
+	}
+
+	E, w, L := 144000., 1., 10.
+	if d := c.Displacements[mem.End().Id].TY; !near(d, -w*L*L*L*L/(8*E)) {
+		T.Errorf("tip deflection %g expected %g", d, -w*L*L*L*L/(8*E))
+	}
+	r := c.Reactions[mem.Begin().Id]
+	if !near(r.FY, w*L) || !near(r.MZ, w*L*L/2) {
+		T.Errorf("reaction %+v expected FY %g MZ %g", r, w*L, w*L*L/2)
+	}
+}
+
+func TestTableAreaLoad(T *testing.T) {
+	m, sec := unitModel()
+	var tops []*model.Node
+	for _, p := range [][2]float64{{0, 0}, {4, 0}, {4, 6}, {0, 6}} {
+		post := m.NewContinuousMember(sec, p[0], 0, p[1], p[0], 3, p[1])
+		post.Begin().FixedSupport()
+		tops = append(tops, post.End())
+	}
+	for i := range tops {
+		m.NewContinuousMemberBetweenNodes(sec, tops[i], tops[(i+1)%len(tops)])
+	}
+	al, err := m.NewAreaLoad(tops...)
+	if err != nil {
+		T.Fatal(err)
+	}
+	al.LoadGroup = "dead"
+	al.Direction = "Y"
+	al.Mag = -0.5
+
+	m.LoadCombinations.Mapping.DeadCases("dead")
+	m.LoadCombinations.Cases = []model.Case{{Name: "1.4D", Dead: 1.4}}
+
+	res, err := Solve(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	c := res.Case("1.4D")
+
+	sum := 0.
+	for _, r := range c.Reactions {
+		sum += r.FY
+	}
+	if expected := 1.4 * 0.5 * 4 * 6; !near(sum, expected) {
+		T.Errorf("vertical reactions sum to %g expected %g", sum, expected)
+	}
+	for _, r := range c.Reactions {
+		if !near(r.FY, sum/4) {
+			T.Errorf("symmetric table has unequal reactions %+v", c.Reactions)
+			break
+		}
+	}
+}
+
+func TestUnstable(T *testing.T) {
+	m, sec := unitModel()
+	m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0)
+	m.NewSelfWeight()
+
+	if _, err := Solve(m); !errors.Is(err, ErrUnstable) {
+		T.Errorf("unsupported member solved with error %v", err)
+	}
+}
+
+func TestOneWaySupport(T *testing.T) {
+	m, sec := unitModel()
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0)
+	mem.Begin().FixedSupport()
+	if _, err := mem.End().DirectionalSupport(model.RestraintPinned, "BPBBBB"); err != nil {
+		T.Fatal(err)
+	}
+	m.NewSelfWeight()
+
+	if _, err := Solve(m); !errors.Is(err, ErrDirections) {
+		T.Errorf("one way support solved with error %v", err)
+	}
+}
+
+func TestPinnedBeam(T *testing.T) {
+	m, sec := unitModel()
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0).Pin()
+	if _, err := mem.Split(4); err != nil {
+		T.Fatal(err)
+	}
+	mem.Begin().FixedSupport()
+	mem.End().FixedSupport()
+	m.NewSelfWeight()
+
+	res, err := Solve(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	c := res.Case("SW1")
+
+	w, L := 1., 10.
+	for _, n := range []*model.Node{mem.Begin(), mem.End()} {
+		if r := c.Reactions[n.Id]; !near(r.FY, w*L/2) || !near(r.MZ, 0) {
+			T.Errorf("pinned end reaction %+v expected FY %g and no moment", r, w*L/2)
+		}
+	}
+	// the interior node stays continuous so the moment there is wx(L-x)/2
+	if f := c.Members[1].B; !near(math.Abs(f.MomentZ), w*4*6/2) {
+		T.Errorf("moment at split %g expected %g", f.MomentZ, w*4*6/2)
+	}
+}
+
+func TestOffsetCantilever(T *testing.T) {
+	m, sec := unitModel()
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0)
+	mem.OffsetA = model.Vector{X: 2}
+	mem.Begin().FixedSupport()
+	m.NewSelfWeight()
+
+	res, err := Solve(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	c := res.Case("SW1")
+
+	E, w, L := 144000., 1., 8.
+	if d := c.Displacements[mem.End().Id].TY; !near(d, -w*L*L*L*L/(8*E)) {
+		T.Errorf("tip deflection %g expected %g", d, -w*L*L*L*L/(8*E))
+	}
+	if r := c.Reactions[mem.Begin().Id]; !near(r.FY, w*L) || !near(r.MZ, w*L*(2+L/2)) {
+		T.Errorf("reaction %+v expected FY %g MZ %g", r, w*L, w*L*(2+L/2))
+	}
+}
+
+func TestSpringSupport(T *testing.T) {
+	m, sec := unitModel()
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0)
+	mem.Begin().PinnedSupport()
+	mem.End().SpringSupport(0, 100, math.Inf(1), math.Inf(1), 0, 0)
+	m.NewSelfWeight()
+
+	res, err := Solve(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+	c := res.Case("SW1")
+
+	// the spring carries half the weight of the simply supported beam
+	w, L := 1., 10.
+	if r := c.Reactions[mem.End().Id]; !near(r.FY, w*L/2) {
+		T.Errorf("spring reaction %+v expected FY %g", r, w*L/2)
+	}
+	if d := c.Displacements[mem.End().Id].TY; !near(d, -w*L/2/100) {
+		T.Errorf("spring displacement %g expected %g", d, -w*L/2/100)
+	}
+}
+
+func fixedBeam() (*model.Skyciv, *model.ContinuousMember) {
+	m, sec := unitModel()
+	mem := m.NewContinuousMember(sec, 0, 0, 0, 10, 0, 0)
+	mem.Begin().FixedSupport()
+	mem.End().FixedSupport()
+	return m, mem
+}
+
+func TestMemberPointLoad(T *testing.T) {
+	m, mem := fixedBeam()
+	if _, err := m.NewMemberPointLoad(mem, 5, "Y", -2, "live"); err != nil {
+		T.Fatal(err)
+	}
+	split, splitMem := fixedBeam()
+	if _, err := splitMem.PointLoad(5, "Y", -2, "live"); err != nil {
+		T.Fatal(err)
+	}
+
+	P, L := 2., 10.
+	for _, sm := range []*model.Skyciv{m, split} {
+		res, err := Solve(sm)
+		if err != nil {
+			T.Fatal(err)
+		}
+		r := res.Case("live").Reactions[mem.Begin().Id]
+		if !near(r.FY, P/2) || !near(r.MZ, P*L/8) {
+			T.Errorf("reaction %+v expected FY %g MZ %g", r, P/2, P*L/8)
+		}
+	}
+}
+
+func TestDistributedLoad(T *testing.T) {
+	m, mem := fixedBeam()
+	if _, err := mem.Split(3); err != nil {
+		T.Fatal(err)
+	}
+	if _, err := m.NewDistributedLoad(mem, 0, 10, "Y", -1, -1, "dead"); err != nil {
+		T.Fatal(err)
+	}
+	if _, err := m.NewDistributedLoad(mem, 2, 8, "Y", 0, -3, "snow"); err != nil {
+		T.Fatal(err)
+	}
+
+	res, err := Solve(m)
+	if err != nil {
+		T.Fatal(err)
+	}
+
+	w, L := 1., 10.
+	if r := res.Case("dead").Reactions[mem.Begin().Id]; !near(r.FY, w*L/2) || !near(r.MZ, w*L*L/12) {
+		T.Errorf("uniform load reaction %+v expected FY %g MZ %g", r, w*L/2, w*L*L/12)
+	}
+
+	snow := res.Case("snow")
+	sum := snow.Reactions[mem.Begin().Id].FY + snow.Reactions[mem.End().Id].FY
+	if !near(sum, 3*6/2.) {
+		T.Errorf("triangular load reactions sum to %g expected %g", sum, 3*6/2.)
+	}
+}
@@ -0,0 +1,170 @@
+package units
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// System is the unit of each kind of quantity in a skyciv model, as in the
+// units settings of a model or the units block of materials.json.  Skyciv
+// also accepts the name of a preset system in place of the units.
+type System struct {
+	Length           string `json:"length"`
+	SectionLength    string `json:"section_length"`
+	MaterialStrength string `json:"material_strength"`
+	Density          string `json:"density"`
+	Force            string `json:"force"`
+	Moment           string `json:"moment"`
+	Pressure         string `json:"pressure"`
+	Mass             string `json:"mass"`
+	Translation      string `json:"translation"`
+	Stress           string `json:"stress"`
+	// Name of the preset system, which is written in place of the units
+	Name string `json:"-"`
+}
+
+// the preset systems skyciv knows by name
+var (
+	Imperial = System{
+		Length:           "ft",
+		SectionLength:    "in",
+		MaterialStrength: "ksi",
+		Density:          "lb/ft3",
+		Force:            "kip",
+		Moment:           "kip-ft",
+		Pressure:         "ksf",
+		Mass:             "kip",
+		Translation:      "in",
+		Stress:           "ksi",
+		Name:             "imperial",
+	}
+	Metric = System{
+		Length:           "m",
+		SectionLength:    "mm",
+		MaterialStrength: "MPa",
+		Density:          "kg/m3",
+		Force:            "kN",
+		Moment:           "kN-m",
+		Pressure:         "kPa",
+		Mass:             "kg",
+		Translation:      "mm",
+		Stress:           "MPa",
+		Name:             "metric",
+	}
+	presets = []System{Imperial, Metric}
+)
+
+// Preset finds a preset system by name
+func Preset(name string) (System, error) {
+	for _, s := range presets {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return System{}, fmt.Errorf("unknown unit system %q, expected imperial or metric", name)
+}
+
+// Unit is the unit of a kind of quantity in the system
+func (s System) Unit(kind Kind) string {
+	switch kind {
+	case KindLength:
+		return s.Length
+	case KindSectionLength:
+		return s.SectionLength
+	case KindMaterialStrength:
+		return s.MaterialStrength
+	case KindDensity:
+		return s.Density
+	case KindForce:
+		return s.Force
+	case KindMoment:
+		return s.Moment
+	case KindPressure:
+		return s.Pressure
+	case KindMass:
+		return s.Mass
+	case KindTranslation:
+		return s.Translation
+	case KindStress:
+		return s.Stress
+	}
+	return ""
+}
+
+// Kinds lists every kind of quantity of a system
+func Kinds() []Kind {
+	return []Kind{
+		KindLength, KindSectionLength, KindMaterialStrength, KindDensity, KindForce,
+		KindMoment, KindPressure, KindMass, KindTranslation, KindStress,
+	}
+}
+
+// Validate checks that every unit of the system is known
+func (s System) Validate() error {
+	for _, k := range Kinds() {
+		if _, err := Parse(k, s.Unit(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Conversion scales quantities of each kind from one system to another
+type Conversion map[Kind]float64
+
+// To finds the factors that convert quantities in s to t
+func (s System) To(t System) (Conversion, error) {
+	c := make(Conversion)
+	for _, k := range Kinds() {
+		from, err := Parse(k, s.Unit(k))
+		if err != nil {
+			return nil, err
+		}
+		to, err := Parse(k, t.Unit(k))
+		if err != nil {
+			return nil, err
+		}
+		c[k] = from / to
+	}
+	return c, nil
+}
+
+// Identity is true when the conversion leaves every quantity alone
+func (c Conversion) Identity() bool {
+	for _, f := range c {
+		if f != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// system has the fields of System without its json methods
+type system System
+
+func (s System) MarshalJSON() ([]byte, error) {
+	if s.Name != "" {
+		return json.Marshal(s.Name)
+	}
+	return json.Marshal(system(s))
+}
+
+// UnmarshalJSON reads either the name of a preset system or the unit of each
+// kind of quantity
+func (s *System) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		p, err := Preset(name)
+		if err != nil {
+			return err
+		}
+		*s = p
+		return nil
+	}
+	var raw system
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*s = System(raw)
+	return nil
+}
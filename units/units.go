@@ -0,0 +1,169 @@
+// Package units has typed physical quantities and the unit systems skyciv
+// models and materials.json files are written in.
+//
+// Like time.Duration each quantity is a float64 in an SI base unit, so
+// quantities are built by multiplying a number by a unit and read back with
+// In:
+//
+//	load := 20 * units.PoundPerSquareFoot
+//	load.In(units.KipPerSquareFoot) // 0.02
+package units
+
+import (
+	"fmt"
+)
+
+// Length in meters
+type Length float64
+
+const (
+	Meter      Length = 1
+	Centimeter Length = 0.01
+	Millimeter Length = 0.001
+	Foot       Length = 0.3048
+	Inch       Length = Foot / 12
+)
+
+func (l Length) In(unit Length) float64 { return float64(l / unit) }
+
+// Force in newtons
+type Force float64
+
+const (
+	Newton     Force = 1
+	Kilonewton Force = 1000
+	PoundForce Force = 4.4482216152605
+	Kip        Force = 1000 * PoundForce
+)
+
+func (f Force) In(unit Force) float64 { return float64(f / unit) }
+
+// Moment in newton meters
+type Moment float64
+
+const (
+	NewtonMeter     Moment = 1
+	KilonewtonMeter Moment = 1000
+	PoundFoot       Moment = Moment(PoundForce) * Moment(Foot)
+	PoundInch       Moment = Moment(PoundForce) * Moment(Inch)
+	KipFoot         Moment = Moment(Kip) * Moment(Foot)
+	KipInch         Moment = Moment(Kip) * Moment(Inch)
+)
+
+func (m Moment) In(unit Moment) float64 { return float64(m / unit) }
+
+// Pressure, and stress, in pascals
+type Pressure float64
+
+const (
+	Pascal             Pressure = 1
+	Kilopascal         Pressure = 1e3
+	Megapascal         Pressure = 1e6
+	Gigapascal         Pressure = 1e9
+	PoundPerSquareFoot Pressure = Pressure(PoundForce) / Pressure(Foot*Foot)
+	KipPerSquareFoot   Pressure = 1000 * PoundPerSquareFoot
+	PoundPerSquareInch Pressure = Pressure(PoundForce) / Pressure(Inch*Inch)
+	KipPerSquareInch   Pressure = 1000 * PoundPerSquareInch
+)
+
+func (p Pressure) In(unit Pressure) float64 { return float64(p / unit) }
+
+// Mass in kilograms
+type Mass float64
+
+const (
+	Kilogram Mass = 1
+	Tonne    Mass = 1000
+	Pound    Mass = 0.45359237
+	// Kilopound is the mass that weighs a kip
+	Kilopound Mass = 1000 * Pound
+)
+
+func (m Mass) In(unit Mass) float64 { return float64(m / unit) }
+
+// Density in kilograms per cubic meter
+type Density float64
+
+const (
+	KilogramPerCubicMeter Density = 1
+	PoundPerCubicFoot     Density = Density(Pound) / Density(Foot*Foot*Foot)
+)
+
+func (d Density) In(unit Density) float64 { return float64(d / unit) }
+
+// Speed in meters per second
+type Speed float64
+
+const (
+	MeterPerSecond   Speed = 1
+	KilometerPerHour Speed = 1000. / 3600.
+	FootPerSecond    Speed = Speed(Foot)
+	MilePerHour      Speed = 5280 * Speed(Foot) / 3600
+)
+
+func (s Speed) In(unit Speed) float64 { return float64(s / unit) }
+
+// Kind is a kind of quantity in a unit system, named by its key in skyciv's
+// units settings
+type Kind string
+
+const (
+	KindLength           Kind = "length"
+	KindSectionLength    Kind = "section_length"
+	KindMaterialStrength Kind = "material_strength"
+	KindDensity          Kind = "density"
+	KindForce            Kind = "force"
+	KindMoment           Kind = "moment"
+	KindPressure         Kind = "pressure"
+	KindMass             Kind = "mass"
+	KindTranslation      Kind = "translation"
+	KindStress           Kind = "stress"
+)
+
+// the units skyciv understands for each kind of quantity, in SI base units
+var (
+	lengths = map[string]float64{
+		"m": float64(Meter), "cm": float64(Centimeter), "mm": float64(Millimeter),
+		"ft": float64(Foot), "in": float64(Inch),
+	}
+	pressures = map[string]float64{
+		"Pa": float64(Pascal), "kPa": float64(Kilopascal), "MPa": float64(Megapascal), "GPa": float64(Gigapascal),
+		"psf": float64(PoundPerSquareFoot), "ksf": float64(KipPerSquareFoot),
+		"psi": float64(PoundPerSquareInch), "ksi": float64(KipPerSquareInch),
+	}
+	byKind = map[Kind]map[string]float64{
+		KindLength:           lengths,
+		KindSectionLength:    lengths,
+		KindTranslation:      lengths,
+		KindMaterialStrength: pressures,
+		KindPressure:         pressures,
+		KindStress:           pressures,
+		KindDensity: {
+			"kg/m3": float64(KilogramPerCubicMeter), "lb/ft3": float64(PoundPerCubicFoot),
+		},
+		KindForce: {
+			"N": float64(Newton), "kN": float64(Kilonewton), "lb": float64(PoundForce), "kip": float64(Kip),
+		},
+		KindMoment: {
+			"N-m": float64(NewtonMeter), "kN-m": float64(KilonewtonMeter),
+			"lb-ft": float64(PoundFoot), "lb-in": float64(PoundInch),
+			"kip-ft": float64(KipFoot), "kip-in": float64(KipInch),
+		},
+		KindMass: {
+			"kg": float64(Kilogram), "t": float64(Tonne), "lb": float64(Pound), "kip": float64(Kilopound),
+		},
+	}
+)
+
+// Parse finds the size of a unit of the kind in SI base units
+func Parse(kind Kind, unit string) (float64, error) {
+	units, ok := byKind[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown kind of quantity %q", kind)
+	}
+	size, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown %s unit %q", kind, unit)
+	}
+	return size, nil
+}
@@ -0,0 +1,82 @@
+package units
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol*math.Max(1, math.Abs(b))
+}
+
+func TestQuantities(T *testing.T) {
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"ft in m", Foot.In(Meter), 0.3048},
+		{"psf in ksf", (20 * PoundPerSquareFoot).In(KipPerSquareFoot), 0.02},
+		{"ksi in MPa", KipPerSquareInch.In(Megapascal), 6.894757293168},
+		{"ksf in kPa", KipPerSquareFoot.In(Kilopascal), 47.880258980335846},
+		{"kip-ft in kN-m", KipFoot.In(KilonewtonMeter), 1.3558179483314},
+		{"pcf in kg/m3", PoundPerCubicFoot.In(KilogramPerCubicMeter), 16.018463373960138},
+		{"mph in m/s", MilePerHour.In(MeterPerSecond), 0.44704},
+	} {
+		if !near(c.got, c.want, 1e-12) {
+			T.Errorf("%s is %v instead of %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestConversion(T *testing.T) {
+	c, err := Imperial.To(Metric)
+	if err != nil {
+		T.Fatal(err)
+	}
+	if !near(c[KindLength], 0.3048, 1e-12) || !near(c[KindSectionLength], 25.4, 1e-12) {
+		T.Errorf("lengths convert by %v and %v", c[KindLength], c[KindSectionLength])
+	}
+	if !near(c[KindForce], 4.4482216152605, 1e-12) {
+		T.Errorf("kip converts to %v kN", c[KindForce])
+	}
+	if c, _ := Imperial.To(Imperial); !c.Identity() {
+		T.Errorf("imperial to imperial is %v", c)
+	}
+
+	bad := Imperial
+	bad.Force = "furlong"
+	if _, err := bad.To(Metric); err == nil {
+		T.Errorf("unknown force unit accepted")
+	}
+}
+
+func TestSystemJSON(T *testing.T) {
+	var s System
+	if err := json.Unmarshal([]byte(`"metric"`), &s); err != nil {
+		T.Fatal(err)
+	} else if s != Metric {
+		T.Errorf("metric read as %+v", s)
+	}
+	if err := json.Unmarshal([]byte(`"cubits"`), &s); err == nil {
+		T.Errorf("unknown preset accepted")
+	}
+
+	// a units block reads as its units and writes them back
+	block := `{"length":"ft","section_length":"in","material_strength":"ksi","density":"lb/ft3","force":"kip","moment":"kip-ft","pressure":"ksf","mass":"kip","translation":"in","stress":"ksi"}`
+	s = System{}
+	if err := json.Unmarshal([]byte(block), &s); err != nil {
+		T.Fatal(err)
+	}
+	if s.Name != "" || s.Length != "ft" || s.Stress != "ksi" {
+		T.Errorf("units block read as %+v", s)
+	}
+	if b, err := json.Marshal(s); err != nil {
+		T.Fatal(err)
+	} else if string(b) != block {
+		T.Errorf("units block written as %s", b)
+	}
+	if b, _ := json.Marshal(Imperial); string(b) != `"imperial"` {
+		T.Errorf("imperial written as %s", b)
+	}
+}